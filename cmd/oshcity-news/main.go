@@ -3,29 +3,50 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"oshcity-news-parser/internal/checksum"
 	"oshcity-news-parser/internal/storage"
+	"oshcity-news-parser/internal/storage/migrate"
 	"oshcity-news-parser/internal/storage/mssql"
+	"oshcity-news-parser/internal/storage/postgres"
+	"oshcity-news-parser/internal/storage/sqlite"
 	"time"
 
 	"oshcity-news-parser/internal/app"
 	"oshcity-news-parser/internal/config"
 	"oshcity-news-parser/internal/fetcher"
+	"oshcity-news-parser/internal/images"
+	"oshcity-news-parser/internal/normalize"
 	"oshcity-news-parser/internal/observability"
+	"oshcity-news-parser/internal/scheduler"
 	"oshcity-news-parser/internal/scraper"
 )
 
 func main() {
+	// Подкоманда управления схемой: oshcity-news migrate up|status [config]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	configPath := "configs/config.yaml"
 	saveDebugPages := false
+	backfill := false
 
 	if len(os.Args) > 1 {
 		configPath = os.Args[1]
 	}
-	if len(os.Args) > 2 && os.Args[2] == "--debug-pages" {
-		saveDebugPages = true
+	for _, arg := range os.Args[2:] {
+		switch arg {
+		case "--debug-pages":
+			saveDebugPages = true
+		case "--backfill":
+			backfill = true
+		}
 	}
 
 	// Загружаем конфиг
@@ -44,8 +65,19 @@ func main() {
 	)
 	logger.Info("Application started", "config", configPath)
 
+	// Код выхода выставляется после отложенных закрытий ресурсов (этот defer выполняется последним)
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
 	// Инициализируем fetcher
-	f := fetcher.NewFetcher(cfg, logger)
+	f, err := fetcher.NewFetcher(cfg, logger)
+	if err != nil {
+		log.Fatalf("Failed to initialize fetcher: %v", err)
+	}
 	defer func() {
 		logger.Info("Closing fetcher")
 		if err := f.Close(); err != nil {
@@ -54,9 +86,125 @@ func main() {
 	}()
 
 	// Инициализируем Repository
-	var repo storage.Repository
-	if cfg.Storage.Driver == "mssql" {
-		mssqlRepo, err := mssql.NewRepository(
+	repo, err := openRepository(cfg, logger)
+	if err != nil {
+		log.Fatalf("Failed to initialize repository: %v", err)
+	}
+	defer func() {
+		logger.Info("Closing repository")
+		if err := repo.Close(); err != nil {
+			logger.Error("Failed to close repository", "error", err.Error())
+		}
+	}()
+
+	// Схема БД не должна отставать от миграций бинарника
+	migrator, err := repo.Migrator()
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		log.Fatalf("Database schema check failed: %v", err)
+	}
+
+	// Регистрируем языки из конфига
+	if err := repo.RegisterLanguages(context.Background(), languageNames(cfg)); err != nil {
+		log.Fatalf("Failed to register languages: %v", err)
+	}
+
+	// Инициализируем generator checksum
+	checksumGen := checksum.NewGenerator()
+
+	// Инициализируем normalizer детальных страниц
+	normalizer := normalize.NewNormalizer(cfg)
+
+	// Инициализируем загрузчик миниатюр (nil — загрузка отключена)
+	var imgDownloader *images.Downloader
+	if cfg.Images.Enabled {
+		imgDownloader = images.NewDownloader(cfg, f, logger)
+	}
+
+	// Настраиваем graceful shutdown
+	shutdownTimeout := time.Duration(cfg.Scheduler.GracefulShutdownTimeoutS) * time.Second
+	mainCtx, mainCancel, forced := app.GracefulShutdown(logger, shutdownTimeout)
+	defer mainCancel()
+
+	// Метрики: /metrics и периодический снимок в metrics_path
+	if cfg.Observability.MetricsListen != "" {
+		observability.StartMetricsServer(mainCtx, cfg.Observability.MetricsListen, logger)
+	}
+	if cfg.Observability.MetricsPath != "" {
+		metricsDone := make(chan struct{})
+		metricsCtx, metricsCancel := context.WithCancel(context.Background())
+		go func() {
+			defer close(metricsDone)
+			observability.RunMetricsDumper(metricsCtx, cfg.Observability.MetricsPath, cfg.GetMetricsDumpInterval(), logger)
+		}()
+		// Финальный снимок после завершения всех проходов
+		defer func() {
+			metricsCancel()
+			<-metricsDone
+		}()
+	}
+
+	// Разовая догрузка архива по sitemap вместо планировщика
+	if backfill {
+		finished, err := app.UntilForced(forced, func() error {
+			return runBackfill(mainCtx, cfg, logger, f, normalizer, imgDownloader, repo, checksumGen)
+		})
+		if !finished {
+			exitCode = 1
+			return
+		}
+		if err != nil {
+			logger.Error("Backfill finished with error", "error", err.Error())
+		}
+		logger.Info("Application finished")
+		return
+	}
+
+	// Инициализируем планировщик
+	sched, err := scheduler.NewScheduler(cfg, logger)
+	if err != nil {
+		log.Fatalf("Failed to initialize scheduler: %v", err)
+	}
+
+	// Статус проходов и health-эндпоинты
+	tracker := app.NewStatusTracker()
+	if cfg.Observability.HealthListen != "" {
+		app.NewHealthServer(cfg.Observability.HealthListen, logger, repo, f, tracker, sched.NextRun).Start(mainCtx)
+	}
+
+	job := func(ctx context.Context) error {
+		return runPass(ctx, cfg, logger, f, normalizer, imgDownloader, repo, checksumGen, tracker, saveDebugPages)
+	}
+
+	finished, err := app.UntilForced(forced, func() error {
+		return sched.Run(mainCtx, job)
+	})
+	if !finished {
+		exitCode = 1
+		return
+	}
+	if err != nil {
+		logger.Error("Scheduler finished with error", "error", err.Error())
+	}
+
+	logger.Info("Application finished")
+}
+
+// managedRepository — Repository драйвера с управлением схемой и соединением
+type managedRepository interface {
+	storage.Repository
+	Migrator() (*migrate.Runner, error)
+	RegisterLanguages(ctx context.Context, languages []string) error
+	Close() error
+}
+
+// openRepository создаёт Repository для storage.driver
+func openRepository(cfg *config.Config, logger *observability.Logger) (managedRepository, error) {
+	switch cfg.Storage.Driver {
+	case "mssql":
+		repo, err := mssql.NewRepository(
 			cfg.Storage.DSN,
 			cfg.Storage.CommandTimeoutMS,
 			cfg.Storage.MaxOpenConnections,
@@ -66,88 +214,235 @@ func main() {
 			logger,
 		)
 		if err != nil {
-			log.Fatalf("Failed to initialize MS SQL repository: %v", err)
+			return nil, fmt.Errorf("failed to initialize MS SQL repository: %w", err)
 		}
-		defer func() {
-			logger.Info("Closing repository")
-			if err := mssqlRepo.Close(); err != nil {
-				logger.Error("Failed to close repository", "error", err.Error())
-			}
-		}()
-		repo = mssqlRepo
-	} else {
-		log.Fatalf("Unsupported storage driver: %s", cfg.Storage.Driver)
+		return repo, nil
+	case "postgres":
+		repo, err := postgres.NewRepository(
+			cfg.Storage.DSN,
+			cfg.Storage.CommandTimeoutMS,
+			cfg.Storage.MaxOpenConnections,
+			cfg.Storage.MaxIdleConnections,
+			cfg.Storage.ConnectionMaxLifetime,
+			cfg.Storage.ConnectionMaxIdleTime,
+			logger,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize PostgreSQL repository: %w", err)
+		}
+		return repo, nil
+	case "sqlite":
+		repo, err := sqlite.NewRepository(cfg.Storage.DSN, cfg.Storage.CommandTimeoutMS, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize SQLite repository: %w", err)
+		}
+		return repo, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Storage.Driver)
 	}
+}
 
-	// Инициализируем generator checksum
-	checksumGen := checksum.NewGenerator()
+// languageNames возвращает коды языков из конфига
+func languageNames(cfg *config.Config) []string {
+	languages := make([]string, 0, len(cfg.Languages))
+	for _, langCfg := range cfg.Languages {
+		languages = append(languages, langCfg.Name)
+	}
+	return languages
+}
 
-	// Настраиваем graceful shutdown
-	shutdownTimeout := time.Duration(cfg.Scheduler.GracefulShutdownTimeoutS) * time.Second
-	mainCtx, mainCancel := app.GracefulShutdown(logger, shutdownTimeout)
-	defer mainCancel()
+// runPass выполняет один проход: пагинация по всем языкам и обновление checksum в БД.
+// Языки обрабатываются параллельно (scheduler.workers) с общим Fetcher и лимитом на хост
+func runPass(
+	ctx context.Context,
+	cfg *config.Config,
+	logger *observability.Logger,
+	f *fetcher.Fetcher,
+	normalizer *normalize.Normalizer,
+	imgDownloader *images.Downloader,
+	repo storage.Repository,
+	checksumGen *checksum.Generator,
+	tracker *app.StatusTracker,
+	saveDebugPages bool,
+) error {
+	workers := cfg.GetSchedulerWorkers()
+	logger.Info("Starting pagination", "languages_count", len(cfg.Languages), "workers", workers)
+
+	langErr := app.RunParallel(ctx, workers, cfg.Languages, func(langCfg config.LanguageConfig) error {
+		return runLanguage(ctx, cfg, logger, f, normalizer, imgDownloader, repo, checksumGen, tracker, saveDebugPages, &langCfg)
+	})
+
+	// Проверяем, не истёк ли main context (graceful shutdown)
+	if ctx.Err() != nil {
+		logger.Info("Shutdown signal detected, stopping language processing")
+		return langErr
+	}
+
+	// Обновляем контрольные суммы новостей в БД
+	logger.Info("Updating news checksums in database")
+	msg, err := repo.UpdateNewsCheckSum(ctx)
+	if err != nil {
+		logger.Error("Failed to update news checksums", "error", err.Error())
+		return errors.Join(langErr, fmt.Errorf("failed to update news checksums: %w", err))
+	}
+	logger.Info("News checksums updated successfully", "message", msg)
+
+	return langErr
+}
 
-	logger.Info("Starting pagination", "languages_count", len(cfg.Languages))
+// runLanguage выполняет пагинацию одного языка; компоненты с состоянием (scraper, orchestrator)
+// создаются на каждый вызов, поэтому языки можно запускать параллельно
+func runLanguage(
+	ctx context.Context,
+	cfg *config.Config,
+	logger *observability.Logger,
+	f *fetcher.Fetcher,
+	normalizer *normalize.Normalizer,
+	imgDownloader *images.Downloader,
+	repo storage.Repository,
+	checksumGen *checksum.Generator,
+	tracker *app.StatusTracker,
+	saveDebugPages bool,
+	langCfg *config.LanguageConfig,
+) error {
+	logger.Info("Processing language", "language", langCfg.Name)
+
+	// Загружаем селекторы
+	selectors, err := cfg.LoadSelectorsForLanguage(langCfg)
+	if err != nil {
+		logger.Error("Failed to load selectors", "language", langCfg.Name, "error", err.Error())
+		return fmt.Errorf("language %s: failed to load selectors: %w", langCfg.Name, err)
+	}
+
+	// Создаём отдельный context для каждого языка с таймаутом из конфига;
+	// он производный от ctx прохода, чтобы сигнал остановки прерывал текущую пагинацию
+	langTimeout := time.Duration(langCfg.TimeoutSeconds) * time.Second
+	langCtx, langCancel := context.WithTimeout(ctx, langTimeout)
+
+	// Создаём компоненты для языка
+	scr := scraper.NewScraper(selectors, cfg.Observability.LogPath, logger)
+	dateParser := scraper.NewDateParser(langCfg.Name)
+	orchestrator := app.NewOrchestrator(cfg, logger, f, scr, dateParser, normalizer, imgDownloader, repo, checksumGen, saveDebugPages)
+
+	// Запускаем пагинацию
+	startedAt := time.Now()
+	stats, err := orchestrator.Run(langCtx, langCfg)
+	langCancel()
+	tracker.RecordRun(langCfg.Name, startedAt, stats, err)
+	recordRunHistory(ctx, logger, repo, langCfg.Name, startedAt, stats, err)
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			logger.Info("Pagination cancelled by shutdown signal", "language", langCfg.Name)
+		} else if errors.Is(err, context.DeadlineExceeded) {
+			logger.Error("Pagination timeout exceeded", "language", langCfg.Name, "timeout_seconds", langCfg.TimeoutSeconds)
+		} else {
+			logger.Error("Pagination failed", "language", langCfg.Name, "error", err.Error())
+		}
+		return fmt.Errorf("language %s: %w", langCfg.Name, err)
+	}
+
+	logger.Info("Pagination completed",
+		"language", langCfg.Name,
+		"total_pages", stats.TotalPages,
+		"total_cards", stats.TotalCards,
+		"old_cards", stats.OldCards,
+		"cached_pages", stats.CachedPages,
+		"new_cards", stats.NewCards,
+		"updated_cards", stats.UpdatedCards,
+		"unchanged_cards", stats.UnchangedCards,
+		"reason", stats.StoppedReason,
+	)
+
+	return nil
+}
+
+// recordRunHistory сохраняет проход по языку в историю запусков в БД
+func recordRunHistory(ctx context.Context, logger *observability.Logger, repo storage.Repository, language string, startedAt time.Time, stats *app.PaginationStats, runErr error) {
+	run := &storage.RunRecord{
+		Language:   language,
+		StartedAt:  startedAt.UTC(),
+		FinishedAt: time.Now().UTC(),
+	}
+	if stats != nil {
+		run.TotalPages = stats.TotalPages
+		run.TotalCards = stats.TotalCards
+		run.OldCards = stats.OldCards
+		run.CachedPages = stats.CachedPages
+		run.NewCards = stats.NewCards
+		run.UpdatedCards = stats.UpdatedCards
+		run.UnchangedCards = stats.UnchangedCards
+		run.StoppedReason = stats.StoppedReason
+	}
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+
+	// Запись делаем и после сигнала остановки — прерванный проход тоже попадает в историю
+	if err := repo.RecordRun(context.WithoutCancel(ctx), run); err != nil {
+		logger.Error("Failed to record run history", "language", language, "error", err.Error())
+	}
+}
+
+// runBackfill догружает архив новостей всех языков по sitemap.xml и обновляет checksum в БД
+func runBackfill(
+	ctx context.Context,
+	cfg *config.Config,
+	logger *observability.Logger,
+	f *fetcher.Fetcher,
+	normalizer *normalize.Normalizer,
+	imgDownloader *images.Downloader,
+	repo storage.Repository,
+	checksumGen *checksum.Generator,
+) error {
+	logger.Info("Starting backfill", "languages_count", len(cfg.Languages))
 
-	// Запускаем пагинацию для каждого языка из конфига
 	for _, langCfg := range cfg.Languages {
-		// Проверяем, не истёк ли main context (graceful shutdown)
 		select {
-		case <-mainCtx.Done():
-			logger.Info("Shutdown signal detected, stopping language processing")
-			break
+		case <-ctx.Done():
+			logger.Info("Shutdown signal detected, stopping backfill")
+			return ctx.Err()
 		default:
 		}
 
-		logger.Info("Processing language", "language", langCfg.Name)
-
-		// Загружаем селекторы
 		selectors, err := cfg.LoadSelectorsForLanguage(&langCfg)
 		if err != nil {
 			logger.Error("Failed to load selectors", "language", langCfg.Name, "error", err.Error())
 			continue
 		}
 
-		// Создаём отдельный context для каждого языка с таймаутом из конфига
-		langTimeout := time.Duration(langCfg.TimeoutSeconds) * time.Second
-		langCtx, langCancel := context.WithTimeout(context.Background(), langTimeout)
-
-		// Создаём компоненты для языка
 		scr := scraper.NewScraper(selectors, cfg.Observability.LogPath, logger)
 		dateParser := scraper.NewDateParser(langCfg.Name)
-		orchestrator := app.NewOrchestrator(cfg, logger, f, scr, dateParser, repo, checksumGen, saveDebugPages)
-
-		// Запускаем пагинацию
-		stats, err := orchestrator.Run(langCtx, &langCfg)
-		langCancel()
+		orchestrator := app.NewOrchestrator(cfg, logger, f, scr, dateParser, normalizer, imgDownloader, repo, checksumGen, false)
 
+		// Backfill не ограничен timeout_seconds языка — архив может быть большим
+		stats, err := orchestrator.Backfill(ctx, &langCfg)
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
-				logger.Info("Pagination cancelled by shutdown signal", "language", langCfg.Name)
-			} else if errors.Is(err, context.DeadlineExceeded) {
-				logger.Error("Pagination timeout exceeded", "language", langCfg.Name, "timeout_seconds", langCfg.TimeoutSeconds)
-			} else {
-				logger.Error("Pagination failed", "language", langCfg.Name, "error", err.Error())
+				logger.Info("Backfill cancelled by shutdown signal", "language", langCfg.Name)
+				return err
 			}
-		} else {
-			logger.Info("Pagination completed",
-				"language", langCfg.Name,
-				"total_pages", stats.TotalPages,
-				"total_cards", stats.TotalCards,
-				"old_cards", stats.OldCards,
-				"reason", stats.StoppedReason,
-			)
+			logger.Error("Backfill failed", "language", langCfg.Name, "error", err.Error())
+			continue
 		}
+
+		logger.Info("Backfill completed",
+			"language", langCfg.Name,
+			"sitemaps", stats.Sitemaps,
+			"urls_found", stats.URLsFound,
+			"skipped", stats.Skipped,
+			"saved", stats.Saved,
+			"failed", stats.Failed,
+		)
 	}
 
-	// Обновляем контрольные суммы новостей в БД
 	logger.Info("Updating news checksums in database")
-	msg, err := repo.UpdateNewsCheckSum(mainCtx)
+	msg, err := repo.UpdateNewsCheckSum(ctx)
 	if err != nil {
 		logger.Error("Failed to update news checksums", "error", err.Error())
-	} else {
-		logger.Info("News checksums updated successfully", "message", msg)
+		return fmt.Errorf("failed to update news checksums: %w", err)
 	}
+	logger.Info("News checksums updated successfully", "message", msg)
 
-	logger.Info("Application finished")
+	return nil
 }
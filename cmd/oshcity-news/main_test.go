@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"oshcity-news-parser/internal/app"
+	"oshcity-news-parser/internal/checksum"
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/fetcher"
+	"oshcity-news-parser/internal/observability"
+	"oshcity-news-parser/internal/storage/memory"
+)
+
+func TestRunLanguageCancelledByParent(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		// Листинг "зависает", пока клиент не отменит запрос
+		requested <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	selectorsFile, err := filepath.Abs("../../configs/selectors.ru.yaml")
+	if err != nil {
+		t.Fatalf("selectors path: %v", err)
+	}
+
+	cfg := &config.Config{
+		HTTP:       config.HttpConfig{ConnectTimeoutMS: 60000, TotalTimeoutMS: 60000},
+		RateLimit:  config.RateLimitConfig{MaxConcurrentPerHost: 1, RPM: 600},
+		Pagination: config.PaginationConfig{Strategy: "links", StopOnKnownChainPages: 1},
+	}
+	langCfg := &config.LanguageConfig{
+		Name:           "ru",
+		BaseURL:        server.URL + "/ru/",
+		SelectorsFile:  selectorsFile,
+		MaxPages:       1,
+		TimeoutSeconds: 60,
+	}
+
+	logger := observability.NewLogger("", "error", 0, 0, 0)
+	f, err := fetcher.NewFetcher(cfg, logger)
+	if err != nil {
+		t.Fatalf("NewFetcher error: %v", err)
+	}
+	repo := memory.NewRepository("ru")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- runLanguage(ctx, cfg, logger, f, nil, nil, repo, checksum.NewGenerator(), app.NewStatusTracker(), false, langCfg)
+	}()
+
+	select {
+	case <-requested:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("listing was not requested")
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("runLanguage error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("runLanguage did not stop after parent context was cancelled")
+	}
+
+	// Прерванный проход попадает в историю запусков
+	if runs := repo.Runs(); len(runs) != 1 || runs[0].Error == "" {
+		t.Errorf("run history = %+v, want one failed run", runs)
+	}
+}
+
+func TestRecordRunHistory(t *testing.T) {
+	repo := memory.NewRepository("ru")
+	stats := &app.PaginationStats{TotalPages: 2, TotalCards: 20, NewCards: 1, UpdatedCards: 2, UnchangedCards: 17, StoppedReason: "max pages reached"}
+
+	recordRunHistory(context.Background(), observability.NewLogger("", "error", 0, 0, 0), repo, "ru", time.Now(), stats, nil)
+
+	runs := repo.Runs()
+	if len(runs) != 1 {
+		t.Fatalf("run history = %+v, want one run", runs)
+	}
+	if run := runs[0]; run.NewCards != 1 || run.UpdatedCards != 2 || run.UnchangedCards != 17 || run.StoppedReason != stats.StoppedReason {
+		t.Errorf("run = %+v, want counters copied from stats", run)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/observability"
+)
+
+// runMigrate — подкоманда migrate: up применяет миграции, status показывает их состояние
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: oshcity-news migrate up|status [config]")
+	}
+
+	action := args[0]
+	configPath := "configs/config.yaml"
+	if len(args) > 1 {
+		configPath = args[1]
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logger := observability.NewLogger(
+		cfg.Observability.LogPath,
+		cfg.Observability.LogLevel,
+		cfg.Observability.MaxLogAgeDays,
+		cfg.Observability.MaxLogSizeMB,
+		cfg.Observability.MaxBackups,
+	)
+
+	repo, err := openRepository(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = repo.Close() }()
+
+	migrator, err := repo.Migrator()
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	ctx := context.Background()
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		if err := repo.RegisterLanguages(ctx, languageNames(cfg)); err != nil {
+			return fmt.Errorf("failed to register languages: %w", err)
+		}
+		logger.Info("Migrations applied",
+			"driver", cfg.Storage.Driver,
+			"applied", applied,
+			"version", migrator.LatestVersion(),
+		)
+		return nil
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED_AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if !status.AppliedAt.IsZero() {
+				appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			_, _ = fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		current, err := migrator.CurrentVersion(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("schema version: %d, binary version: %d\n", current, migrator.LatestVersion())
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate action: %s (expected up or status)", action)
+	}
+}
@@ -0,0 +1,286 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/sitemap"
+	"oshcity-news-parser/internal/storage"
+)
+
+// maxSitemapDepth — глубина вложенности sitemapindex, дальше которой не спускаемся
+const maxSitemapDepth = 3
+
+type BackfillStats struct {
+	Sitemaps  int // Загруженные sitemap (включая sitemapindex)
+	URLsFound int // URL статей языка, найденные в sitemap
+	Skipped   int // Уже сохранённые статьи, не изменившиеся по lastmod
+	Saved     int
+	Failed    int
+}
+
+// Backfill догружает полный архив новостей языка по sitemap.xml: для каждой статьи
+// загружается детальная страница и карточка сохраняется через Repository.
+// Уже сохранённый URL загружается повторно, только если lastmod в sitemap новее
+// последней записи карточки (без lastmod — пропускается): статью, изменённую после
+// сохранения, backfill обновит, а неизменённые не перезапишут позицию и checksum листинга.
+func (o *Orchestrator) Backfill(ctx context.Context, langCfg *config.LanguageConfig) (*BackfillStats, error) {
+	stats := &BackfillStats{}
+
+	urls, err := o.discoverArticleURLs(ctx, langCfg, stats)
+	if err != nil {
+		return stats, err
+	}
+	stats.URLsFound = len(urls)
+
+	o.logger.Info("Starting backfill",
+		"language", langCfg.Name,
+		"sitemaps", stats.Sitemaps,
+		"urls_found", stats.URLsFound,
+		"max_urls", o.cfg.Backfill.MaxURLs,
+	)
+
+	for _, u := range urls {
+		select {
+		case <-ctx.Done():
+			o.logger.Info("Context cancelled, stopping backfill",
+				"language", langCfg.Name,
+				"saved", stats.Saved,
+			)
+			return stats, ctx.Err()
+		default:
+		}
+
+		if o.cfg.Backfill.MaxURLs > 0 && stats.Saved+stats.Failed >= o.cfg.Backfill.MaxURLs {
+			o.logger.Info("Backfill URL limit reached",
+				"language", langCfg.Name,
+				"max_urls", o.cfg.Backfill.MaxURLs,
+			)
+			break
+		}
+
+		updatedAt, exists, err := o.repo.GetUpdatedAt(ctx, u.Loc)
+		if err != nil {
+			o.logger.Warn("Failed to check card existence",
+				"language", langCfg.Name,
+				"url", u.Loc,
+				"error", err.Error(),
+			)
+			stats.Failed++
+			continue
+		}
+		// Нулевой updatedAt — время записи неизвестно: статья с lastmod загружается заново
+		if exists && !u.LastMod.After(updatedAt) {
+			stats.Skipped++
+			continue
+		}
+
+		articleCard, err := o.backfillCard(ctx, langCfg, u)
+		if err != nil {
+			o.logger.Warn("Failed to load article for backfill",
+				"language", langCfg.Name,
+				"url", u.Loc,
+				"error", err.Error(),
+			)
+			stats.Failed++
+			continue
+		}
+
+		// Checksum — как у карточки листинга без превью: Text листинга подставляется из Title
+		if err := o.storeCard(ctx, langCfg, articleCard, articleCard.Title); err != nil {
+			if errors.Is(err, errCardSkipped) {
+				stats.Skipped++
+			} else {
+				stats.Failed++
+			}
+			continue
+		}
+		stats.Saved++
+	}
+
+	o.logger.Info("Backfill completed",
+		"language", langCfg.Name,
+		"urls_found", stats.URLsFound,
+		"skipped", stats.Skipped,
+		"saved", stats.Saved,
+		"failed", stats.Failed,
+	)
+
+	return stats, nil
+}
+
+// discoverArticleURLs обходит sitemap (включая sitemapindex) и возвращает URL статей языка
+func (o *Orchestrator) discoverArticleURLs(ctx context.Context, langCfg *config.LanguageConfig, stats *BackfillStats) ([]sitemap.URL, error) {
+	roots, err := o.sitemapRoots(ctx, langCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	type queued struct {
+		url   string
+		depth int
+	}
+
+	queue := make([]queued, 0, len(roots))
+	for _, root := range roots {
+		queue = append(queue, queued{url: root})
+	}
+
+	prefix := langCfg.BackfillURLPrefix()
+	visited := make(map[string]bool)
+	seen := make(map[string]bool)
+	var urls []sitemap.URL
+
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+
+		if visited[item.url] {
+			continue
+		}
+		visited[item.url] = true
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		resp, err := o.fetcher.FetchSitemap(ctx, item.url)
+		if err != nil {
+			o.logger.Warn("Failed to fetch sitemap", "url", item.url, "error", err.Error())
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			o.logger.Warn("Unexpected sitemap status", "url", item.url, "status", resp.StatusCode)
+			continue
+		}
+
+		doc, err := sitemap.Parse(resp.Body)
+		if err != nil {
+			o.logger.Warn("Failed to parse sitemap", "url", item.url, "error", err.Error())
+			continue
+		}
+		stats.Sitemaps++
+
+		o.logger.Debug("Sitemap parsed",
+			"url", item.url,
+			"urls", len(doc.URLs),
+			"sitemaps", len(doc.Sitemaps),
+		)
+
+		for _, child := range doc.Sitemaps {
+			if item.depth+1 > maxSitemapDepth {
+				o.logger.Warn("Sitemap index too deep, skipping", "url", child)
+				continue
+			}
+			queue = append(queue, queued{url: child, depth: item.depth + 1})
+		}
+
+		for _, u := range doc.URLs {
+			if !isArticleURL(u.Loc, prefix) || seen[u.Loc] {
+				continue
+			}
+			seen[u.Loc] = true
+			urls = append(urls, u)
+		}
+	}
+
+	return urls, nil
+}
+
+// sitemapRoots возвращает корневые sitemap: из конфига, из robots.txt или /sitemap.xml
+func (o *Orchestrator) sitemapRoots(ctx context.Context, langCfg *config.LanguageConfig) ([]string, error) {
+	if len(o.cfg.Backfill.Sitemaps) > 0 {
+		return o.cfg.Backfill.Sitemaps, nil
+	}
+
+	base, err := url.Parse(langCfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base_url: %w", err)
+	}
+
+	roots, err := o.fetcher.Sitemaps(ctx, base.Scheme, base.Host)
+	if err != nil {
+		o.logger.Warn("Failed to read sitemaps from robots.txt",
+			"host", base.Host,
+			"error", err.Error(),
+		)
+	}
+	if len(roots) == 0 {
+		roots = []string{base.Scheme + "://" + base.Host + "/sitemap.xml"}
+	}
+
+	return roots, nil
+}
+
+// isArticleURL — URL относится к статьям языка (под префиксом, но не сам листинг и не его страницы)
+func isArticleURL(loc, prefix string) bool {
+	// Percent-encoding в sitemap и base_url может отличаться регистром (%D2 / %d2)
+	if len(loc) <= len(prefix) || !strings.EqualFold(loc[:len(prefix)], prefix) {
+		return false
+	}
+	return !strings.HasPrefix(loc[len(prefix):], "page/")
+}
+
+// backfillCard загружает детальную страницу и собирает из неё карточку
+func (o *Orchestrator) backfillCard(ctx context.Context, langCfg *config.LanguageConfig, u sitemap.URL) (*storage.ArticleCard, error) {
+	resp, err := o.fetcher.Fetch(ctx, u.Loc, langCfg.AcceptLanguage)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected detail page status: %d", resp.StatusCode)
+	}
+
+	content, err := o.normalizer.ParseDetailPage(string(resp.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse detail page: %w", err)
+	}
+	if content.Title == "" {
+		return nil, fmt.Errorf("detail page has no title")
+	}
+
+	// SequenceNum остаётся 0: позиции в листинге у статьи из архива нет. Если статья
+	// попадёт в листинг, проход обновит SequenceNum и checksum без новой ревизии
+	articleCard := &storage.ArticleCard{
+		CanonicalURL: u.Loc,
+		Title:        content.Title,
+		Text:         o.normalizer.TruncatePreview(content.Text),
+		ImageURL:     content.ImageURL,
+		Language:     langCfg.Name,
+		Body:         content.Text,
+		OgImageURL:   content.ImageURL,
+	}
+	if articleCard.Text == "" {
+		articleCard.Text = articleCard.Title
+	}
+
+	// Дата публикации — с детальной страницы; храним только день, как у листинга
+	var publishedAt time.Time
+	if content.DateRaw != "" {
+		if detailDate, err := o.parseDetailDate(content.DateRaw); err == nil {
+			articleCard.DetailDate = detailDate
+			publishedAt = detailDate
+		}
+	}
+	if publishedAt.IsZero() {
+		// lastmod — время последнего изменения, а не публикации: только в крайнем случае
+		if u.LastMod.IsZero() {
+			return nil, fmt.Errorf("publication date not found")
+		}
+		o.logger.Warn("Publication date not found on detail page, using sitemap lastmod as approximation",
+			"language", langCfg.Name,
+			"url", u.Loc,
+			"lastmod", u.LastMod.Format(time.RFC3339),
+		)
+		publishedAt = u.LastMod
+	}
+	articleCard.Date = time.Date(publishedAt.Year(), publishedAt.Month(), publishedAt.Day(), 0, 0, 0, 0, time.UTC)
+
+	return articleCard, nil
+}
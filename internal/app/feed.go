@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/feed"
+)
+
+// runFeed — инкрементальное получение новостей из RSS/Atom фида (pagination.strategy: feed).
+// Следующие страницы фида WordPress запрашиваются через параметр paged.
+func (o *Orchestrator) runFeed(ctx context.Context, langCfg *config.LanguageConfig, latestKnownDate time.Time) (*PaginationStats, error) {
+	o.logger.Info("Starting feed pagination",
+		"language", langCfg.Name,
+		"feed_url", langCfg.FeedURL,
+		"max_pages", langCfg.MaxPages,
+		"latest_known_date", latestKnownDate.Format("2006-01-02"),
+	)
+
+	stats := &PaginationStats{}
+	consecutiveOldPages := 0
+
+	for pageNum := 1; pageNum <= langCfg.MaxPages; pageNum++ {
+		// Проверяем context на отмену
+		select {
+		case <-ctx.Done():
+			o.logger.Info("Context cancelled, stopping feed pagination",
+				"language", langCfg.Name,
+				"page", pageNum,
+			)
+			stats.StoppedReason = "shutdown signal received"
+			return stats, ctx.Err()
+		default:
+		}
+
+		feedURL, err := feedPageURL(langCfg.FeedURL, pageNum)
+		if err != nil {
+			stats.StoppedReason = fmt.Sprintf("invalid feed url: %v", err)
+			return stats, err
+		}
+
+		resp, err := o.fetcher.FetchFeed(ctx, feedURL, langCfg.AcceptLanguage)
+		if err != nil {
+			o.logger.Error("Feed fetch failed",
+				"language", langCfg.Name,
+				"page", pageNum,
+				"url", feedURL,
+				"error", err.Error(),
+			)
+			stats.StoppedReason = fmt.Sprintf("feed fetch error at page %d: %v", pageNum, err)
+			return stats, err
+		}
+
+		// Страницы за пределами фида WordPress отдаёт как 404
+		if resp.StatusCode == http.StatusNotFound && pageNum > 1 {
+			stats.StoppedReason = fmt.Sprintf("feed ended at page %d", pageNum)
+			break
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := fmt.Errorf("unexpected feed status: %d", resp.StatusCode)
+			stats.StoppedReason = fmt.Sprintf("feed fetch error at page %d: %v", pageNum, err)
+			return stats, err
+		}
+
+		// Фид не изменился с прошлого запуска — новых записей нет
+		if resp.FromCache {
+			stats.TotalPages++
+			stats.CachedPages++
+			stats.StoppedReason = fmt.Sprintf("feed not modified at page %d", pageNum)
+			break
+		}
+
+		cards, err := feed.Parse(resp.Body)
+		if err != nil {
+			o.logger.Error("Feed parse failed",
+				"language", langCfg.Name,
+				"page", pageNum,
+				"error", err.Error(),
+			)
+			stats.StoppedReason = fmt.Sprintf("feed parse error at page %d: %v", pageNum, err)
+			return stats, err
+		}
+
+		if len(cards) == 0 {
+			stats.StoppedReason = fmt.Sprintf("no items on feed page %d", pageNum)
+			break
+		}
+
+		stats.TotalPages++
+		stats.TotalCards += len(cards)
+
+		oldCardsOnPage, persisted, err := o.processCards(ctx, langCfg, cards, pageNum, latestKnownDate)
+		if !persisted {
+			// Иначе следующий проход получит 304 и остановится, не сохранив пропущенные записи
+			o.fetcher.DropCache(feedURL)
+		}
+		if err != nil {
+			stats.StoppedReason = fmt.Sprintf("storage error at feed page %d: %v", pageNum, err)
+			return stats, err
+		}
+		stats.OldCards += oldCardsOnPage
+
+		o.logger.Info("Feed page analysis",
+			"language", langCfg.Name,
+			"page", pageNum,
+			"total_cards", len(cards),
+			"old_cards", oldCardsOnPage,
+			"consecutive_old_pages", consecutiveOldPages,
+		)
+
+		if oldCardsOnPage == len(cards) {
+			consecutiveOldPages++
+			if consecutiveOldPages >= o.cfg.Pagination.StopOnKnownChainPages {
+				stats.StoppedReason = fmt.Sprintf("reached %d consecutive old pages at page %d", o.cfg.Pagination.StopOnKnownChainPages, pageNum)
+				break
+			}
+		} else {
+			consecutiveOldPages = 0
+		}
+	}
+
+	o.logger.Info("Feed pagination completed",
+		"language", langCfg.Name,
+		"total_pages", stats.TotalPages,
+		"total_cards", stats.TotalCards,
+		"old_cards", stats.OldCards,
+		"reason", stats.StoppedReason,
+	)
+
+	return stats, nil
+}
+
+// feedPageURL возвращает URL страницы фида (paged=N для N > 1)
+func feedPageURL(feedURL string, pageNum int) (string, error) {
+	if pageNum == 1 {
+		return feedURL, nil
+	}
+
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("paged", strconv.Itoa(pageNum))
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
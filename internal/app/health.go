@@ -0,0 +1,190 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"oshcity-news-parser/internal/fetcher"
+	"oshcity-news-parser/internal/observability"
+	"oshcity-news-parser/internal/storage"
+)
+
+// readyCheckTimeout — таймаут проверок /readyz
+const readyCheckTimeout = 5 * time.Second
+
+// RunStatus — результат последнего прохода по языку
+type RunStatus struct {
+	Language   string           `json:"language"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+	DurationMS int64            `json:"duration_ms"`
+	Stats      *PaginationStats `json:"stats,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// StatusTracker хранит последний проход по каждому языку для /status
+type StatusTracker struct {
+	mu   sync.RWMutex
+	runs map[string]RunStatus
+}
+
+func NewStatusTracker() *StatusTracker {
+	return &StatusTracker{
+		runs: make(map[string]RunStatus),
+	}
+}
+
+// RecordRun сохраняет результат прохода по языку
+func (t *StatusTracker) RecordRun(language string, startedAt time.Time, stats *PaginationStats, runErr error) {
+	finishedAt := time.Now()
+	status := RunStatus{
+		Language:   language,
+		StartedAt:  startedAt.UTC(),
+		FinishedAt: finishedAt.UTC(),
+		DurationMS: finishedAt.Sub(startedAt).Milliseconds(),
+		Stats:      stats,
+	}
+	if runErr != nil {
+		status.Error = runErr.Error()
+	}
+
+	t.mu.Lock()
+	t.runs[language] = status
+	t.mu.Unlock()
+}
+
+// Runs возвращает последние проходы, отсортированные по языку
+func (t *StatusTracker) Runs() []RunStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	runs := make([]RunStatus, 0, len(t.runs))
+	for _, run := range t.runs {
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Language < runs[j].Language })
+	return runs
+}
+
+// HealthServer — HTTP сервер /healthz, /readyz и /status для режима постоянной работы
+type HealthServer struct {
+	server  *http.Server
+	logger  *observability.Logger
+	repo    storage.Repository
+	fetcher *fetcher.Fetcher
+	tracker *StatusTracker
+	nextRun func() time.Time
+	started time.Time
+}
+
+func NewHealthServer(
+	addr string,
+	logger *observability.Logger,
+	repo storage.Repository,
+	f *fetcher.Fetcher,
+	tracker *StatusTracker,
+	nextRun func() time.Time,
+) *HealthServer {
+	h := &HealthServer{
+		logger:  logger,
+		repo:    repo,
+		fetcher: f,
+		tracker: tracker,
+		nextRun: nextRun,
+		started: time.Now().UTC(),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", h.handleHealthz)
+	mux.HandleFunc("/readyz", h.handleReadyz)
+	mux.HandleFunc("/status", h.handleStatus)
+
+	h.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return h
+}
+
+// Start запускает сервер в фоне; сервер останавливается при отмене ctx
+func (h *HealthServer) Start(ctx context.Context) {
+	go func() {
+		h.logger.Info("Health server started", "addr", h.server.Addr)
+		if err := h.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			h.logger.Error("Health server failed", "addr", h.server.Addr, "error", err.Error())
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = h.server.Shutdown(shutdownCtx)
+	}()
+}
+
+// handleHealthz — процесс жив
+func (h *HealthServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReadyz — доступны БД и (если используется) браузер Rod
+func (h *HealthServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyCheckTimeout)
+	defer cancel()
+
+	checks := map[string]string{
+		"repository": "ok",
+		"browser":    "ok",
+	}
+	status := http.StatusOK
+
+	if err := h.repo.Ping(ctx); err != nil {
+		checks["repository"] = err.Error()
+		status = http.StatusServiceUnavailable
+	}
+	if err := h.fetcher.Ping(ctx); err != nil {
+		checks["browser"] = err.Error()
+		status = http.StatusServiceUnavailable
+	}
+
+	if status != http.StatusOK {
+		h.logger.Warn("Readiness check failed", "checks", checks)
+	}
+
+	writeJSON(w, status, checks)
+}
+
+// handleStatus — последние проходы по языкам и время следующего запуска
+func (h *HealthServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	response := struct {
+		StartedAt time.Time   `json:"started_at"`
+		NextRun   *time.Time  `json:"next_run,omitempty"`
+		Runs      []RunStatus `json:"runs"`
+	}{
+		StartedAt: h.started,
+		Runs:      h.tracker.Runs(),
+	}
+
+	if h.nextRun != nil {
+		if next := h.nextRun(); !next.IsZero() {
+			next = next.UTC()
+			response.NextRun = &next
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/fetcher"
+	"oshcity-news-parser/internal/observability"
+	"oshcity-news-parser/internal/storage"
+	"oshcity-news-parser/internal/storage/memory"
+)
+
+// unavailableRepo — хранилище в памяти, у которого не проходит Ping
+type unavailableRepo struct {
+	*memory.Repository
+}
+
+func (unavailableRepo) Ping(context.Context) error {
+	return errors.New("connection refused")
+}
+
+// newTestHealthServer возвращает HealthServer над repo с HTTP-бэкендом Fetcher
+func newTestHealthServer(t *testing.T, repo storage.Repository, tracker *StatusTracker, nextRun func() time.Time) *HealthServer {
+	t.Helper()
+
+	cfg := &config.Config{
+		HTTP:      config.HttpConfig{ConnectTimeoutMS: 5000, TotalTimeoutMS: 5000},
+		RateLimit: config.RateLimitConfig{MaxConcurrentPerHost: 1, RPM: 60},
+	}
+	logger := observability.NewLogger("", "error", 0, 0, 0)
+	f, err := fetcher.NewFetcher(cfg, logger)
+	if err != nil {
+		t.Fatalf("NewFetcher error: %v", err)
+	}
+	return NewHealthServer(":0", logger, repo, f, tracker, nextRun)
+}
+
+// serve выполняет запрос к серверу и декодирует JSON-ответ в value
+func serve(t *testing.T, h *HealthServer, path string, value any) int {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	h.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json; charset=utf-8" {
+		t.Errorf("%s: Content-Type = %q, want JSON", path, contentType)
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(value); err != nil {
+		t.Fatalf("%s: failed to decode response: %v", path, err)
+	}
+	return recorder.Code
+}
+
+func TestHealthz(t *testing.T) {
+	h := newTestHealthServer(t, unavailableRepo{memory.NewRepository()}, NewStatusTracker(), nil)
+
+	// Процесс жив, даже если БД недоступна
+	var body map[string]string
+	if code := serve(t, h, "/healthz", &body); code != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("/healthz = %d %v, want 200 ok", code, body)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	tests := []struct {
+		name     string
+		repo     storage.Repository
+		wantCode int
+		wantRepo string
+	}{
+		{name: "repository available", repo: memory.NewRepository(), wantCode: http.StatusOK, wantRepo: "ok"},
+		{name: "repository unavailable", repo: unavailableRepo{memory.NewRepository()}, wantCode: http.StatusServiceUnavailable, wantRepo: "connection refused"},
+	}
+
+	for _, tt := range tests {
+		h := newTestHealthServer(t, tt.repo, NewStatusTracker(), nil)
+
+		var checks map[string]string
+		code := serve(t, h, "/readyz", &checks)
+		if code != tt.wantCode {
+			t.Errorf("%s: /readyz = %d, want %d", tt.name, code, tt.wantCode)
+		}
+		if checks["repository"] != tt.wantRepo || checks["browser"] != "ok" {
+			t.Errorf("%s: checks = %v, want repository %q and browser ok", tt.name, checks, tt.wantRepo)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tracker := NewStatusTracker()
+	started := time.Now().Add(-time.Second)
+	tracker.RecordRun("ru", started, &PaginationStats{TotalPages: 2, NewCards: 3}, nil)
+	tracker.RecordRun("kg", started, nil, errors.New("fetch error at page 1"))
+
+	nextRun := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	h := newTestHealthServer(t, memory.NewRepository(), tracker, func() time.Time { return nextRun })
+
+	var body struct {
+		StartedAt time.Time   `json:"started_at"`
+		NextRun   *time.Time  `json:"next_run"`
+		Runs      []RunStatus `json:"runs"`
+	}
+	if code := serve(t, h, "/status", &body); code != http.StatusOK {
+		t.Fatalf("/status = %d, want 200", code)
+	}
+
+	if body.NextRun == nil || !body.NextRun.Equal(nextRun) {
+		t.Errorf("next_run = %v, want %v", body.NextRun, nextRun)
+	}
+	if len(body.Runs) != 2 {
+		t.Fatalf("runs = %+v, want 2", body.Runs)
+	}
+	// Проходы отсортированы по языку
+	if kg := body.Runs[0]; kg.Language != "kg" || kg.Error != "fetch error at page 1" || kg.Stats != nil {
+		t.Errorf("runs[0] = %+v, want failed kg run", kg)
+	}
+	if ru := body.Runs[1]; ru.Language != "ru" || ru.Error != "" || ru.Stats == nil || ru.Stats.NewCards != 3 {
+		t.Errorf("runs[1] = %+v, want successful ru run with 3 new cards", ru)
+	}
+
+	// Без расписания (oneshot) next_run не выводится
+	h = newTestHealthServer(t, memory.NewRepository(), tracker, func() time.Time { return time.Time{} })
+	body.NextRun = nil
+	serve(t, h, "/status", &body)
+	if body.NextRun != nil {
+		t.Errorf("next_run = %v, want omitted", body.NextRun)
+	}
+}
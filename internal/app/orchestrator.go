@@ -2,26 +2,43 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"oshcity-news-parser/internal/checksum"
 	"oshcity-news-parser/internal/storage"
 	"time"
 
 	"oshcity-news-parser/internal/config"
 	"oshcity-news-parser/internal/fetcher"
+	"oshcity-news-parser/internal/images"
+	"oshcity-news-parser/internal/normalize"
 	"oshcity-news-parser/internal/observability"
 	"oshcity-news-parser/internal/scraper"
+	"oshcity-news-parser/internal/wpapi"
 )
 
+// errCardSkipped — карточка не сохранялась: миниатюра не загрузилась, а сохранённую версию
+// нельзя перезаписать checksum без байтов миниатюры
+var errCardSkipped = errors.New("card skipped")
+
 type Orchestrator struct {
 	cfg            *config.Config
 	logger         *observability.Logger
 	fetcher        *fetcher.Fetcher
 	scraper        *scraper.Scraper
 	dateParser     *scraper.DateParser
+	normalizer     *normalize.Normalizer
+	images         *images.Downloader
+	wpClient       *wpapi.Client
 	repo           storage.Repository
 	checksumGen    *checksum.Generator
 	saveDebugPages bool
+
+	// Счётчики сохранённых карточек текущего прохода (для PaginationStats)
+	newCards       int
+	updatedCards   int
+	unchangedCards int
 }
 
 func NewOrchestrator(
@@ -30,6 +47,8 @@ func NewOrchestrator(
 	f *fetcher.Fetcher,
 	s *scraper.Scraper,
 	dp *scraper.DateParser,
+	n *normalize.Normalizer,
+	imgDownloader *images.Downloader,
 	repo storage.Repository,
 	checksumGen *checksum.Generator,
 	saveDebugPages bool,
@@ -40,6 +59,9 @@ func NewOrchestrator(
 		fetcher:        f,
 		scraper:        s,
 		dateParser:     dp,
+		normalizer:     n,
+		images:         imgDownloader,
+		wpClient:       wpapi.NewClient(f, logger),
 		repo:           repo,
 		checksumGen:    checksumGen,
 		saveDebugPages: saveDebugPages,
@@ -47,18 +69,50 @@ func NewOrchestrator(
 }
 
 type PaginationStats struct {
-	TotalPages          int
-	TotalCards          int
-	OldCards            int
-	ConsecutiveOldPages int
-	StoppedReason       string
+	TotalPages          int    `json:"total_pages"`
+	TotalCards          int    `json:"total_cards"`
+	OldCards            int    `json:"old_cards"`
+	ConsecutiveOldPages int    `json:"consecutive_old_pages"`
+	CachedPages         int    `json:"cached_pages"` // Страницы, не изменившиеся с прошлого запуска (HTTP 304)
+	NewCards            int    `json:"new_cards"`
+	UpdatedCards        int    `json:"updated_cards"`
+	UnchangedCards      int    `json:"unchanged_cards"` // Карточки с тем же CheckSum — запись в БД не выполнялась
+	StoppedReason       string `json:"stopped_reason"`
 }
 
 // Run запускает пайплайн пагинации для языка
 func (o *Orchestrator) Run(ctx context.Context, langCfg *config.LanguageConfig) (*PaginationStats, error) {
-	baseURL := langCfg.BaseURL
-	maxPages := langCfg.MaxPages
+	start := time.Now()
+	defer func() {
+		observability.RunDuration.WithLabelValues(langCfg.Name).Observe(time.Since(start).Seconds())
+	}()
+
+	o.newCards, o.updatedCards, o.unchangedCards = 0, 0, 0
+	latestKnownDate := o.latestKnownDate(ctx, langCfg)
+
+	var stats *PaginationStats
+	var err error
+	switch {
+	case langCfg.WPAPI.Enabled:
+		// Источник WordPress REST API вместо HTML-скрапинга
+		stats, err = o.runWPAPI(ctx, langCfg, latestKnownDate)
+	case o.cfg.Pagination.Strategy == "feed":
+		stats, err = o.runFeed(ctx, langCfg, latestKnownDate)
+	default:
+		stats, err = o.runLinks(ctx, langCfg, latestKnownDate)
+	}
+
+	if stats != nil {
+		stats.NewCards = o.newCards
+		stats.UpdatedCards = o.updatedCards
+		stats.UnchangedCards = o.unchangedCards
+	}
+
+	return stats, err
+}
 
+// latestKnownDate возвращает дату, начиная с которой карточки считаются новыми
+func (o *Orchestrator) latestKnownDate(ctx context.Context, langCfg *config.LanguageConfig) time.Time {
 	// Получаем default latestKnownDate из конфига
 	latestKnownDate := time.Now().UTC().AddDate(0, 0, -o.cfg.Pagination.DaysBackThreshold).Truncate(24 * time.Hour)
 
@@ -76,9 +130,27 @@ func (o *Orchestrator) Run(ctx context.Context, langCfg *config.LanguageConfig)
 		}
 	}
 
+	return latestKnownDate
+}
+
+// runLinks — пагинация HTML-листинга: по ссылкам "следующая страница" (pagination.strategy: links)
+// или по номеру страницы из page_url_template (pagination.strategy: template)
+func (o *Orchestrator) runLinks(ctx context.Context, langCfg *config.LanguageConfig, latestKnownDate time.Time) (*PaginationStats, error) {
+	baseURL := langCfg.BaseURL
+	maxPages := langCfg.MaxPages
+	useTemplate := o.cfg.Pagination.Strategy == "template"
+
+	// В режиме template обход можно продолжить с произвольной страницы
+	startPage := 1
+	if useTemplate && langCfg.StartPage > 1 {
+		startPage = langCfg.StartPage
+	}
+
 	o.logger.Info("Starting pagination",
 		"language", langCfg.Name,
 		"base_url", baseURL,
+		"strategy", o.cfg.Pagination.Strategy,
+		"start_page", startPage,
 		"max_pages", maxPages,
 		"latest_known_date", latestKnownDate.Format("2006-01-02"),
 		"stop_on_chain_pages", o.cfg.Pagination.StopOnKnownChainPages,
@@ -86,9 +158,12 @@ func (o *Orchestrator) Run(ctx context.Context, langCfg *config.LanguageConfig)
 
 	stats := &PaginationStats{}
 	currentURL := baseURL
+	if useTemplate {
+		currentURL = langCfg.PageURL(startPage)
+	}
 	consecutiveOldPages := 0
 
-	for pageNum := 1; pageNum <= maxPages; pageNum++ {
+	for pageNum := startPage; pageNum < startPage+maxPages; pageNum++ {
 		// Проверяем context на отмену
 		select {
 		case <-ctx.Done():
@@ -107,8 +182,8 @@ func (o *Orchestrator) Run(ctx context.Context, langCfg *config.LanguageConfig)
 			"url", currentURL,
 		)
 
-		// Фетчим страницу
-		resp, err := o.fetcher.Fetch(ctx, currentURL, langCfg.AcceptLanguage)
+		// Фетчим страницу (в Rod-режиме она готова, когда появились карточки)
+		resp, err := o.fetcher.FetchPage(ctx, currentURL, langCfg.AcceptLanguage, o.scraper.CardSelector())
 		if err != nil {
 			o.logger.Error("Fetch failed",
 				"language", langCfg.Name,
@@ -120,110 +195,77 @@ func (o *Orchestrator) Run(ctx context.Context, langCfg *config.LanguageConfig)
 			return stats, err
 		}
 
-		// Парсим листинг
-		cards, err := o.scraper.ParseListing(string(resp.Body), langCfg.Name, pageNum, o.saveDebugPages)
-		if err != nil {
-			o.logger.Error("Parse listing failed",
+		// Номер страницы за пределами листинга — WordPress отвечает 404
+		if useTemplate && resp.StatusCode == http.StatusNotFound {
+			o.logger.Info("Page not found, listing ended",
 				"language", langCfg.Name,
 				"page", pageNum,
-				"error", err.Error(),
 			)
-			stats.StoppedReason = fmt.Sprintf("parse error at page %d: %v", pageNum, err)
-			return stats, err
+			stats.StoppedReason = fmt.Sprintf("page %d not found", pageNum)
+			break
 		}
 
-		if len(cards) == 0 {
-			o.logger.Info("No cards found on page",
+		// Страница не изменилась с прошлого запуска (304) — её карточки уже обработаны,
+		// считаем её полностью "старой" и переходим к следующей
+		allCardsOld := true
+		if resp.FromCache {
+			stats.TotalPages++
+			stats.CachedPages++
+			o.logger.Info("Page not modified, skipping card processing",
 				"language", langCfg.Name,
 				"page", pageNum,
 			)
-			stats.StoppedReason = fmt.Sprintf("no cards on page %d", pageNum)
-			break
-		}
-
-		stats.TotalPages++
-		stats.TotalCards += len(cards)
-
-		// Проверяем, сколько карточек старые
-		oldCardsOnPage := 0
-		for i, card := range cards {
-			cardDate, err := o.dateParser.Parse(card.DateRaw)
+		} else {
+			// Парсим листинг
+			cards, err := o.scraper.ParseListing(string(resp.Body), langCfg.Name, pageNum, o.saveDebugPages)
 			if err != nil {
-				o.logger.Warn("Failed to parse card date",
+				o.logger.Error("Parse listing failed",
 					"language", langCfg.Name,
 					"page", pageNum,
-					"card_title", card.Title,
-					"date_raw", card.DateRaw,
 					"error", err.Error(),
 				)
-				continue
+				stats.StoppedReason = fmt.Sprintf("parse error at page %d: %v", pageNum, err)
+				return stats, err
 			}
 
-			// Debug: выводим информацию по каждой карточке
-			o.logger.Debug("Card info",
-				"language", langCfg.Name,
-				"page", pageNum,
-				"card_num", i+1,
-				"date", cardDate.Format("2006-01-02"),
-				"title", card.Title,
-				"url", card.URL,
-				"thumbnail_url", card.ThumbnailURL,
-			)
-
-			// Если карточка новая — сохраняем в БД
-			if !cardDate.Before(latestKnownDate) {
-				articleCard := &storage.ArticleCard{
-					CanonicalURL: card.URL,
-					Title:        card.Title,
-					Text:         card.Text,
-					ImageURL:     card.ThumbnailURL,
-					Date:         cardDate,
-					Language:     langCfg.Name,
-					SequenceNum:  card.SequenceNum,
-					CheckSum:     o.checksumGen.GenerateContentHash(card.SequenceNum, cardDate.Format("2006-01-02"), card.Title, card.Text, []byte{}),
-				}
-
-				/*o.logger.Debug("Card field lengths",
-					"url_len", len(articleCard.CanonicalURL),
-					"title_len", len(articleCard.Title),
-					"text_len", len(articleCard.Text),
-					"image_url_len", len(articleCard.ImageURL),
-					"checksum_len", len(articleCard.CheckSum),
-				)*/
-
-				isNew, isUpdated, err := o.repo.UpsertCard(ctx, articleCard)
-				if err != nil {
-					o.logger.Error("Failed to upsert card",
-						"language", langCfg.Name,
-						"url", card.URL,
-						"error", err.Error(),
-					)
-				} else {
-					if isNew {
-						o.logger.Debug("Card saved (new)", "url", card.URL)
-					} else if isUpdated {
-						o.logger.Debug("Card updated", "url", card.URL)
-					}
-				}
+			if len(cards) == 0 {
+				o.logger.Info("No cards found on page",
+					"language", langCfg.Name,
+					"page", pageNum,
+				)
+				stats.StoppedReason = fmt.Sprintf("no cards on page %d", pageNum)
+				break
 			}
 
-			if cardDate.Before(latestKnownDate) {
-				oldCardsOnPage++
+			stats.TotalPages++
+			stats.TotalCards += len(cards)
+
+			// Проверяем, сколько карточек старые, и сохраняем новые
+			oldCardsOnPage, persisted, err := o.processCards(ctx, langCfg, cards, pageNum, latestKnownDate)
+			if !persisted {
+				// Без записи в кэше страница придёт целиком (не 304) и её карточки сохранятся повторно
+				o.fetcher.DropCache(currentURL)
+			}
+			if err != nil {
+				stats.StoppedReason = fmt.Sprintf("storage error at page %d: %v", pageNum, err)
+				return stats, err
 			}
-		}
 
-		stats.OldCards += oldCardsOnPage
+			stats.OldCards += oldCardsOnPage
 
-		o.logger.Info("Page analysis",
-			"language", langCfg.Name,
-			"page", pageNum,
-			"total_cards", len(cards),
-			"old_cards", oldCardsOnPage,
-			"consecutive_old_pages", consecutiveOldPages,
-		)
+			o.logger.Info("Page analysis",
+				"language", langCfg.Name,
+				"page", pageNum,
+				"total_cards", len(cards),
+				"old_cards", oldCardsOnPage,
+				"consecutive_old_pages", consecutiveOldPages,
+			)
+
+			allCardsOld = oldCardsOnPage == len(cards)
+		}
 
 		// Проверяем, все ли карточки на странице старые
-		if oldCardsOnPage == len(cards) {
+		if allCardsOld {
 			consecutiveOldPages++
 			o.logger.Info("All cards on page are old",
 				"language", langCfg.Name,
@@ -249,6 +291,12 @@ func (o *Orchestrator) Run(ctx context.Context, langCfg *config.LanguageConfig)
 			)
 		}
 
+		// Следующая страница по шаблону — не зависим от наличия ссылки "следующая"
+		if useTemplate {
+			currentURL = langCfg.PageURL(pageNum + 1)
+			continue
+		}
+
 		// Ищем ссылку на следующую страницу
 		nextLink, err := o.scraper.FindNextPageLink(string(resp.Body))
 		if err != nil {
@@ -283,8 +331,312 @@ func (o *Orchestrator) Run(ctx context.Context, langCfg *config.LanguageConfig)
 		"total_pages", stats.TotalPages,
 		"total_cards", stats.TotalCards,
 		"old_cards", stats.OldCards,
+		"cached_pages", stats.CachedPages,
 		"reason", stats.StoppedReason,
 	)
 
 	return stats, nil
 }
+
+// processCards разбирает даты карточек, сохраняет новые и возвращает количество старых.
+// persisted = false — часть новых карточек не сохранена (ошибка БД или пропуск из-за миниатюры),
+// страницу нужно обработать повторно в следующем проходе
+func (o *Orchestrator) processCards(ctx context.Context, langCfg *config.LanguageConfig, cards []*scraper.Card, pageNum int, latestKnownDate time.Time) (int, bool, error) {
+	oldCardsOnPage := 0
+	persisted := true
+	var pending []*storage.ArticleCard
+	observability.CardsParsed.WithLabelValues(langCfg.Name).Add(float64(len(cards)))
+	for i, card := range cards {
+		cardDate, err := o.cardDate(card)
+		if err != nil {
+			o.logger.Warn("Failed to parse card date",
+				"language", langCfg.Name,
+				"page", pageNum,
+				"card_title", card.Title,
+				"date_raw", card.DateRaw,
+				"error", err.Error(),
+			)
+			observability.CardsSkipped.WithLabelValues(langCfg.Name, "date_error").Inc()
+			continue
+		}
+
+		// Debug: выводим информацию по каждой карточке
+		o.logger.Debug("Card info",
+			"language", langCfg.Name,
+			"page", pageNum,
+			"card_num", i+1,
+			"date", cardDate.Format("2006-01-02"),
+			"title", card.Title,
+			"url", card.URL,
+			"thumbnail_url", card.ThumbnailURL,
+		)
+
+		// Если карточка новая — готовим к сохранению в БД
+		if !cardDate.Before(latestKnownDate) {
+			if articleCard := o.buildCard(ctx, langCfg, card, cardDate); articleCard != nil {
+				pending = append(pending, articleCard)
+			} else {
+				persisted = false
+			}
+		}
+
+		if cardDate.Before(latestKnownDate) {
+			oldCardsOnPage++
+			observability.CardsSkipped.WithLabelValues(langCfg.Name, "old").Inc()
+		}
+	}
+
+	// Новые карточки страницы сохраняются пакетно
+	if err := o.storeCards(ctx, langCfg, pending); err != nil {
+		return oldCardsOnPage, false, err
+	}
+
+	return oldCardsOnPage, persisted, nil
+}
+
+// cardDate возвращает дату карточки: готовую (API/фид) или разобранную из DateRaw
+func (o *Orchestrator) cardDate(card *scraper.Card) (time.Time, error) {
+	if !card.PublishedAt.IsZero() {
+		return card.PublishedAt, nil
+	}
+	return o.dateParser.Parse(card.DateRaw)
+}
+
+// buildCard собирает ArticleCard из карточки листинга: детальная страница, миниатюра, checksum.
+// nil — карточку в этом проходе сохранять нельзя (см. errCardSkipped)
+func (o *Orchestrator) buildCard(ctx context.Context, langCfg *config.LanguageConfig, card *scraper.Card, cardDate time.Time) *storage.ArticleCard {
+	articleCard := &storage.ArticleCard{
+		CanonicalURL: card.URL,
+		Title:        card.Title,
+		Text:         card.Text,
+		ImageURL:     card.ThumbnailURL,
+		Date:         cardDate,
+		Language:     langCfg.Name,
+		SequenceNum:  card.SequenceNum,
+	}
+
+	// Checksum считается по тексту листинга: превью с детальной страницы есть только
+	// в первом проходе, и с ним checksum менялся бы в каждом следующем
+	listingText := articleCard.Text
+
+	// Детальная страница — только для карточек, которых ещё нет в БД
+	if o.cfg.Detail.Enabled {
+		exists, err := o.repo.ExistsByURL(ctx, card.URL)
+		if err != nil {
+			o.logger.Warn("Failed to check card existence",
+				"language", langCfg.Name,
+				"url", card.URL,
+				"error", err.Error(),
+			)
+		} else if !exists {
+			o.enrichFromDetailPage(ctx, langCfg, articleCard)
+		} else if needsDetailPreview(articleCard) {
+			// Пустой Text — в БД остаётся превью, взятое с детальной страницы при вставке
+			articleCard.Text = ""
+		}
+	}
+
+	if err := o.prepareCard(ctx, langCfg, articleCard, listingText); err != nil {
+		return nil
+	}
+	return articleCard
+}
+
+// prepareCard загружает миниатюру и считает checksum карточки.
+// Если миниатюра не загрузилась, checksum без её байтов отличался бы от сохранённого и
+// при следующей удачной загрузке вернулся бы обратно: такая карточка сохраняется, только
+// если её ещё нет в БД, иначе возвращается errCardSkipped.
+// checksumText — текст для checksum (превью листинга, а не детальной страницы)
+func (o *Orchestrator) prepareCard(ctx context.Context, langCfg *config.LanguageConfig, articleCard *storage.ArticleCard, checksumText string) error {
+	// Байты миниатюры участвуют в checksum (формат OshSanaripWebSiteProcessor)
+	imageBytes := []byte{}
+	if o.images != nil && articleCard.ImageURL != "" {
+		data, err := o.images.Download(ctx, articleCard.ImageURL)
+		if err != nil {
+			o.logger.Warn("Failed to download thumbnail",
+				"language", langCfg.Name,
+				"url", articleCard.CanonicalURL,
+				"thumbnail_url", articleCard.ImageURL,
+				"error", err.Error(),
+			)
+			if o.storedCard(ctx, langCfg, articleCard.CanonicalURL) {
+				observability.CardsSkipped.WithLabelValues(langCfg.Name, "thumbnail_error").Inc()
+				return errCardSkipped
+			}
+		} else {
+			imageBytes = data
+			if o.cfg.Images.StoreInDB {
+				articleCard.ImageData = data
+			}
+		}
+	}
+
+	articleCard.CheckSum = o.checksumGen.GenerateContentHash(articleCard.SequenceNum, articleCard.Date.Format("2006-01-02"), articleCard.Title, checksumText, imageBytes)
+	return nil
+}
+
+// storedCard проверяет, сохранена ли карточка; при ошибке БД считаем, что сохранена —
+// пропустить карточку безопаснее, чем перезаписать её checksum
+func (o *Orchestrator) storedCard(ctx context.Context, langCfg *config.LanguageConfig, url string) bool {
+	exists, err := o.repo.ExistsByURL(ctx, url)
+	if err != nil {
+		o.logger.Warn("Failed to check card existence",
+			"language", langCfg.Name,
+			"url", url,
+			"error", err.Error(),
+		)
+		return true
+	}
+	return exists
+}
+
+// storeCard готовит и сохраняет одну карточку в БД (checksumText — см. prepareCard)
+func (o *Orchestrator) storeCard(ctx context.Context, langCfg *config.LanguageConfig, articleCard *storage.ArticleCard, checksumText string) error {
+	if err := o.prepareCard(ctx, langCfg, articleCard, checksumText); err != nil {
+		return err
+	}
+
+	result, err := o.repo.UpsertCard(ctx, articleCard)
+	if err != nil {
+		o.logger.Error("Failed to upsert card",
+			"language", langCfg.Name,
+			"url", articleCard.CanonicalURL,
+			"error", err.Error(),
+		)
+		observability.Upserts.WithLabelValues(langCfg.Name, "failed").Inc()
+		return err
+	}
+
+	o.recordUpsert(langCfg, articleCard, result)
+	return nil
+}
+
+// storeCards сохраняет карточки страницы: одной транзакцией на страницу (storage.tx_per_page)
+// или транзакциями по storage.batch_size карточек. При ошибке откатывается весь пакет;
+// остальные пакеты сохраняются, ошибки возвращаются вместе
+func (o *Orchestrator) storeCards(ctx context.Context, langCfg *config.LanguageConfig, articleCards []*storage.ArticleCard) error {
+	batchSize := o.cfg.Storage.BatchSize
+	if o.cfg.Storage.TxPerPage || batchSize <= 0 {
+		batchSize = len(articleCards)
+	}
+
+	var errs []error
+	for start := 0; start < len(articleCards); start += batchSize {
+		batch := articleCards[start:min(start+batchSize, len(articleCards))]
+
+		results, err := o.repo.UpsertCards(ctx, batch)
+		if err != nil {
+			o.logger.Error("Failed to upsert cards batch, rolled back",
+				"language", langCfg.Name,
+				"batch_size", len(batch),
+				"first_url", batch[0].CanonicalURL,
+				"error", err.Error(),
+			)
+			observability.Upserts.WithLabelValues(langCfg.Name, "failed").Add(float64(len(batch)))
+			errs = append(errs, fmt.Errorf("failed to upsert cards batch starting at %s: %w", batch[0].CanonicalURL, err))
+			continue
+		}
+
+		for i, result := range results {
+			o.recordUpsert(langCfg, batch[i], result)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// recordUpsert учитывает результат сохранения карточки в статистике прохода и метриках
+func (o *Orchestrator) recordUpsert(langCfg *config.LanguageConfig, articleCard *storage.ArticleCard, result storage.UpsertResult) {
+	switch result {
+	case storage.UpsertInserted:
+		o.logger.Debug("Card saved (new)", "url", articleCard.CanonicalURL)
+		o.newCards++
+	case storage.UpsertUpdated:
+		o.logger.Debug("Card updated", "url", articleCard.CanonicalURL)
+		o.updatedCards++
+	default:
+		o.logger.Debug("Card unchanged", "url", articleCard.CanonicalURL)
+		o.unchangedCards++
+	}
+	observability.Upserts.WithLabelValues(langCfg.Name, result.String()).Inc()
+}
+
+// enrichFromDetailPage загружает детальную страницу карточки и дополняет
+// ArticleCard полным текстом, og:image и датой публикации.
+// Ошибки не прерывают сохранение карточки — остаются данные листинга.
+func (o *Orchestrator) enrichFromDetailPage(ctx context.Context, langCfg *config.LanguageConfig, articleCard *storage.ArticleCard) {
+	resp, err := o.fetcher.Fetch(ctx, articleCard.CanonicalURL, langCfg.AcceptLanguage)
+	if err != nil {
+		o.logger.Warn("Failed to fetch detail page",
+			"language", langCfg.Name,
+			"url", articleCard.CanonicalURL,
+			"error", err.Error(),
+		)
+		return
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		o.logger.Warn("Unexpected detail page status",
+			"language", langCfg.Name,
+			"url", articleCard.CanonicalURL,
+			"status", resp.StatusCode,
+		)
+		return
+	}
+
+	content, err := o.normalizer.ParseDetailPage(string(resp.Body))
+	if err != nil {
+		o.logger.Warn("Failed to parse detail page",
+			"language", langCfg.Name,
+			"url", articleCard.CanonicalURL,
+			"error", err.Error(),
+		)
+		return
+	}
+
+	articleCard.Body = content.Text
+	articleCard.OgImageURL = content.ImageURL
+
+	// Если в листинге не было превью (Text подставлен из Title) — берём начало тела статьи
+	if content.Text != "" && needsDetailPreview(articleCard) {
+		articleCard.Text = o.normalizer.TruncatePreview(content.Text)
+	}
+
+	if content.DateRaw != "" {
+		detailDate, err := o.parseDetailDate(content.DateRaw)
+		if err != nil {
+			o.logger.Debug("Failed to parse detail page date",
+				"language", langCfg.Name,
+				"url", articleCard.CanonicalURL,
+				"date_raw", content.DateRaw,
+				"error", err.Error(),
+			)
+		} else {
+			articleCard.DetailDate = detailDate
+		}
+	}
+
+	o.logger.Debug("Detail page parsed",
+		"language", langCfg.Name,
+		"url", articleCard.CanonicalURL,
+		"body_len", len(articleCard.Body),
+		"og_image", articleCard.OgImageURL,
+		"detail_date", articleCard.DetailDate.Format("2006-01-02"),
+	)
+}
+
+// needsDetailPreview — в листинге нет превью карточки (Text пустой или подставлен из Title)
+func needsDetailPreview(articleCard *storage.ArticleCard) bool {
+	return articleCard.Text == "" || articleCard.Text == articleCard.Title
+}
+
+// parseDetailDate парсит дату детальной страницы: ISO 8601 (meta/time) или RU/KY формат
+func (o *Orchestrator) parseDetailDate(dateRaw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, dateRaw); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", dateRaw); err == nil {
+		return t, nil
+	}
+	return o.dateParser.Parse(dateRaw)
+}
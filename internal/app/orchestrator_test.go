@@ -0,0 +1,405 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"oshcity-news-parser/internal/checksum"
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/fetcher"
+	"oshcity-news-parser/internal/images"
+	"oshcity-news-parser/internal/normalize"
+	"oshcity-news-parser/internal/observability"
+	"oshcity-news-parser/internal/scraper"
+	"oshcity-news-parser/internal/storage"
+	"oshcity-news-parser/internal/storage/memory"
+)
+
+// newTestOrchestrator возвращает Orchestrator над хранилищем в памяти и сайтом handler
+func newTestOrchestrator(t *testing.T, cfg *config.Config, handler http.Handler) (*Orchestrator, *memory.Repository, *httptest.Server) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg.HTTP = config.HttpConfig{ConnectTimeoutMS: 5000, TotalTimeoutMS: 5000}
+	cfg.RateLimit = config.RateLimitConfig{MaxConcurrentPerHost: 1, RPM: 6000}
+
+	logger := observability.NewLogger("", "error", 0, 0, 0)
+	f, err := fetcher.NewFetcher(cfg, logger)
+	if err != nil {
+		t.Fatalf("NewFetcher error: %v", err)
+	}
+
+	var imgDownloader *images.Downloader
+	if cfg.Images.Enabled {
+		imgDownloader = images.NewDownloader(cfg, f, logger)
+	}
+
+	repo := memory.NewRepository("ru")
+	o := NewOrchestrator(cfg, logger, f, nil, scraper.NewDateParser("ru"), normalize.NewNormalizer(cfg), imgDownloader, repo, checksum.NewGenerator(), false)
+	return o, repo, server
+}
+
+func TestStoreCardThumbnailError(t *testing.T) {
+	var thumbnailMissing atomic.Bool
+	cfg := &config.Config{Images: config.ImagesConfig{Enabled: true, CacheDir: t.TempDir(), MaxSizeKB: 64}}
+	o, repo, server := newTestOrchestrator(t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if thumbnailMissing.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("image " + r.URL.Path))
+	}))
+
+	ctx := context.Background()
+	langCfg := &config.LanguageConfig{Name: "ru"}
+	newCard := func(n string) *storage.ArticleCard {
+		return &storage.ArticleCard{
+			CanonicalURL: server.URL + "/ru/novosti/" + n + "/",
+			Title:        "Новость " + n,
+			Text:         "Текст",
+			ImageURL:     server.URL + "/uploads/" + n + ".jpg",
+			Date:         time.Date(2025, 10, 18, 0, 0, 0, 0, time.UTC),
+			Language:     "ru",
+		}
+	}
+
+	stored := newCard("1")
+	if err := o.storeCard(ctx, langCfg, stored, stored.Text); err != nil {
+		t.Fatalf("storeCard error: %v", err)
+	}
+
+	// Миниатюры больше нет в дисковом кэше и на сервере
+	cfg.Images.CacheDir = t.TempDir()
+	o.images = images.NewDownloader(cfg, o.fetcher, o.logger)
+	thumbnailMissing.Store(true)
+
+	// Сохранённая карточка пропускается — её checksum не меняется
+	if err := o.storeCard(ctx, langCfg, newCard("1"), "Текст"); !errors.Is(err, errCardSkipped) {
+		t.Errorf("storeCard(stored) error = %v, want errCardSkipped", err)
+	}
+	revisions, err := repo.ListRevisions(ctx, stored.CanonicalURL)
+	if err != nil {
+		t.Fatalf("ListRevisions error: %v", err)
+	}
+	if len(revisions) != 0 || o.updatedCards != 0 {
+		t.Errorf("revisions = %d, updated = %d; want stored card untouched", len(revisions), o.updatedCards)
+	}
+
+	// Новая карточка сохраняется без миниатюры
+	if err := o.storeCard(ctx, langCfg, newCard("2"), "Текст"); err != nil {
+		t.Errorf("storeCard(new) error: %v", err)
+	}
+	if o.newCards != 2 {
+		t.Errorf("newCards = %d, want 2", o.newCards)
+	}
+}
+
+// listingHTML — страница листинга в разметке selectors.ru.yaml
+// (excerpt = false — без превью, как в листингах, где текст берётся с детальной страницы)
+func listingHTML(baseURL string, date time.Time, excerpt bool, titles ...string) string {
+	var html strings.Builder
+	html.WriteString(`<div class="elementor-posts-container">`)
+	for _, title := range titles {
+		excerptHTML := ""
+		if excerpt {
+			excerptHTML = `<div class="elementor-post__excerpt"><p>Текст ` + title + `</p></div>`
+		}
+		fmt.Fprintf(&html, `<article class="elementor-post">
+			<h3 class="elementor-post__title"><a href="%s">%s</a></h3>
+			<span class="elementor-post-date">%s</span>
+			%s
+		</article>`, articleURL(baseURL, title), title, date.Format("02.01.2006"), excerptHTML)
+	}
+	html.WriteString(`</div>`)
+	return html.String()
+}
+
+// articleURL — URL статьи с заголовком title в listingHTML
+func articleURL(baseURL, title string) string {
+	return baseURL + "/ru/novosti/" + url.PathEscape(title) + "/"
+}
+
+// newListingScraper — scraper с селекторами русского листинга из configs
+func newListingScraper(t *testing.T) *scraper.Scraper {
+	t.Helper()
+
+	selectors, err := config.LoadSelectors("../../configs/selectors.ru.yaml")
+	if err != nil {
+		t.Fatalf("LoadSelectors error: %v", err)
+	}
+	// Отладочные карточки пишутся в каталог рядом с debugDir — во временный каталог теста
+	return scraper.NewScraper(selectors, filepath.Join(t.TempDir(), "logs"), observability.NewLogger("", "error", 0, 0, 0))
+}
+
+func TestRunLinksDropsCacheWhenStoreFails(t *testing.T) {
+	var conditional atomic.Int32
+	var baseURL string
+	cfg := &config.Config{
+		HTTPCache:  config.HTTPCacheConfig{Enabled: true, Dir: t.TempDir()},
+		Pagination: config.PaginationConfig{Strategy: "links", StopOnKnownChainPages: 1, DaysBackThreshold: 30},
+	}
+	o, _, server := newTestOrchestrator(t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(listingHTML(baseURL, time.Now().UTC(), true, "Первая", "Вторая")))
+	}))
+	baseURL = server.URL
+	o.scraper = newListingScraper(t)
+
+	// Язык не зарегистрирован — пакет карточек откатывается
+	repo := memory.NewRepository()
+	o.repo = repo
+	langCfg := &config.LanguageConfig{Name: "ru", BaseURL: server.URL + "/ru/", MaxPages: 1}
+
+	if _, err := o.Run(context.Background(), langCfg); err == nil {
+		t.Fatalf("Run: expected storage error")
+	}
+
+	// Повторный проход получает страницу целиком и сохраняет карточки
+	if err := repo.RegisterLanguages(context.Background(), []string{"ru"}); err != nil {
+		t.Fatalf("RegisterLanguages error: %v", err)
+	}
+	stats, err := o.Run(context.Background(), langCfg)
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if conditional.Load() != 0 || stats.CachedPages != 0 {
+		t.Errorf("conditional requests = %d, cached pages = %d; want page refetched", conditional.Load(), stats.CachedPages)
+	}
+	if stats.NewCards != 2 {
+		t.Errorf("NewCards = %d, want 2", stats.NewCards)
+	}
+
+	// После успешного сохранения страница снова берётся из кэша
+	stats, err = o.Run(context.Background(), langCfg)
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if stats.CachedPages != 1 {
+		t.Errorf("CachedPages = %d, want 1 after cards persisted", stats.CachedPages)
+	}
+}
+
+func TestRunLinksKeepsDetailPreview(t *testing.T) {
+	var baseURL string
+	var listing atomic.Value
+	cfg := &config.Config{
+		Detail:     config.DetailConfig{Enabled: true},
+		Normalize:  config.NormalizeConfig{MaxPreviewChars: 40},
+		Pagination: config.PaginationConfig{Strategy: "links", StopOnKnownChainPages: 1, DaysBackThreshold: 30},
+	}
+	o, repo, server := newTestOrchestrator(t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ru/" {
+			_, _ = w.Write([]byte(listing.Load().(string)))
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body><article><p>Полный текст статьи ` + r.URL.Path + `</p></article></body></html>`))
+	}))
+	baseURL = server.URL
+	o.scraper = newListingScraper(t)
+	if err := repo.RegisterLanguages(context.Background(), []string{"ru"}); err != nil {
+		t.Fatalf("RegisterLanguages error: %v", err)
+	}
+	langCfg := &config.LanguageConfig{Name: "ru", BaseURL: server.URL + "/ru/", MaxPages: 1}
+	today := time.Now().UTC()
+
+	listing.Store(listingHTML(baseURL, today, false, "Первая"))
+	stats, err := o.Run(context.Background(), langCfg)
+	if err != nil {
+		t.Fatalf("first Run error: %v", err)
+	}
+	if stats.NewCards != 1 {
+		t.Fatalf("NewCards = %d, want 1", stats.NewCards)
+	}
+
+	// Карточка сдвигается в листинге новой статьёй: текст детальной страницы остаётся, ревизии нет
+	listing.Store(listingHTML(baseURL, today, false, "Новая", "Первая"))
+	stats, err = o.Run(context.Background(), langCfg)
+	if err != nil {
+		t.Fatalf("second Run error: %v", err)
+	}
+	if stats.NewCards != 1 || stats.UpdatedCards != 0 {
+		t.Errorf("new = %d, updated = %d; want 1 new and no updates", stats.NewCards, stats.UpdatedCards)
+	}
+
+	revisions, err := repo.ListRevisions(context.Background(), articleURL(baseURL, "Первая"))
+	if err != nil {
+		t.Fatalf("ListRevisions error: %v", err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("revisions = %+v, want none", revisions)
+	}
+}
+
+func TestBackfillSkipsUnmodifiedStoredCards(t *testing.T) {
+	var baseURL string
+	var detailRequests atomic.Int32
+	var modifiedTitle atomic.Value
+	modifiedTitle.Store("")
+	today := time.Now().UTC()
+	titles := []string{"Первая", "Вторая"}
+	cfg := &config.Config{
+		Detail:     config.DetailConfig{Enabled: true},
+		Normalize:  config.NormalizeConfig{MaxPreviewChars: 40},
+		Pagination: config.PaginationConfig{Strategy: "links", StopOnKnownChainPages: 1, DaysBackThreshold: 30},
+	}
+	o, repo, server := newTestOrchestrator(t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			var sitemap strings.Builder
+			sitemap.WriteString(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+			for _, title := range titles {
+				lastMod := today
+				if title == modifiedTitle.Load().(string) {
+					lastMod = time.Now().UTC().Add(time.Hour)
+				}
+				fmt.Fprintf(&sitemap, `<url><loc>%s</loc><lastmod>%s</lastmod></url>`, articleURL(baseURL, title), lastMod.Format(time.RFC3339))
+			}
+			sitemap.WriteString(`</urlset>`)
+			_, _ = w.Write([]byte(sitemap.String()))
+		case "/ru/":
+			_, _ = w.Write([]byte(listingHTML(baseURL, today, false, titles...)))
+		default:
+			detailRequests.Add(1)
+			title := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/ru/novosti/"), "/")
+			fmt.Fprintf(w, `<html><head><meta property="article:published_time" content="%s"></head>
+				<body><h1>%s</h1><article><p>Полный текст статьи %s</p></article></body></html>`, today.Format(time.RFC3339), title, title)
+		}
+	}))
+	baseURL = server.URL
+	cfg.Backfill.Sitemaps = []string{server.URL + "/sitemap.xml"}
+	o.scraper = newListingScraper(t)
+	if err := repo.RegisterLanguages(context.Background(), []string{"ru"}); err != nil {
+		t.Fatalf("RegisterLanguages error: %v", err)
+	}
+	langCfg := &config.LanguageConfig{Name: "ru", BaseURL: server.URL + "/ru/", MaxPages: 1}
+
+	stats, err := o.Backfill(context.Background(), langCfg)
+	if err != nil {
+		t.Fatalf("Backfill error: %v", err)
+	}
+	if stats.Saved != 2 {
+		t.Fatalf("Saved = %d, want 2", stats.Saved)
+	}
+
+	// Повторный backfill не загружает сохранённые статьи, не изменённые после записи
+	requests := detailRequests.Load()
+	stats, err = o.Backfill(context.Background(), langCfg)
+	if err != nil {
+		t.Fatalf("second Backfill error: %v", err)
+	}
+	if stats.Skipped != 2 || stats.Saved != 0 || detailRequests.Load() != requests {
+		t.Errorf("skipped = %d, saved = %d, detail requests = %d; want stored cards skipped", stats.Skipped, stats.Saved, detailRequests.Load()-requests)
+	}
+
+	// Проход по листингу видит те же карточки без изменений и ревизий
+	runStats, err := o.Run(context.Background(), langCfg)
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if runStats.NewCards != 0 || runStats.UpdatedCards != 0 {
+		t.Errorf("new = %d, updated = %d; want backfilled cards unchanged", runStats.NewCards, runStats.UpdatedCards)
+	}
+	for _, title := range titles {
+		revisions, err := repo.ListRevisions(context.Background(), articleURL(baseURL, title))
+		if err != nil {
+			t.Fatalf("ListRevisions error: %v", err)
+		}
+		if len(revisions) != 0 {
+			t.Errorf("%s: revisions = %+v, want none", title, revisions)
+		}
+	}
+
+	// lastmod статьи новее её записи в БД — backfill загружает её заново
+	modifiedTitle.Store("Вторая")
+	requests = detailRequests.Load()
+	stats, err = o.Backfill(context.Background(), langCfg)
+	if err != nil {
+		t.Fatalf("third Backfill error: %v", err)
+	}
+	if stats.Skipped != 1 || stats.Saved != 1 || detailRequests.Load()-requests != 1 {
+		t.Errorf("skipped = %d, saved = %d, detail requests = %d; want only the modified article reloaded", stats.Skipped, stats.Saved, detailRequests.Load()-requests)
+	}
+}
+
+func TestRunTemplateStopsOnNotFound(t *testing.T) {
+	var baseURL string
+	today := time.Now().UTC()
+	pages := map[string][]string{
+		"/ru/":        {"Первая", "Вторая"},
+		"/ru/page/2/": {"Третья", "Четвёртая"},
+		"/ru/page/3/": {"Пятая"},
+	}
+
+	tests := []struct {
+		name       string
+		startPage  int
+		wantPaths  []string
+		wantCards  int
+		wantReason string
+	}{
+		{name: "from first page", startPage: 0, wantPaths: []string{"/ru/", "/ru/page/2/", "/ru/page/3/", "/ru/page/4/"}, wantCards: 5, wantReason: "page 4 not found"},
+		{name: "from start_page", startPage: 2, wantPaths: []string{"/ru/page/2/", "/ru/page/3/", "/ru/page/4/"}, wantCards: 3, wantReason: "page 4 not found"},
+	}
+
+	for _, tt := range tests {
+		var requested []string
+		cfg := &config.Config{Pagination: config.PaginationConfig{Strategy: "template", StopOnKnownChainPages: 1, DaysBackThreshold: 30}}
+		o, repo, server := newTestOrchestrator(t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = append(requested, r.URL.Path)
+			titles, ok := pages[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(listingHTML(baseURL, today, true, titles...)))
+		}))
+		baseURL = server.URL
+		o.scraper = newListingScraper(t)
+		if err := repo.RegisterLanguages(context.Background(), []string{"ru"}); err != nil {
+			t.Fatalf("RegisterLanguages error: %v", err)
+		}
+		langCfg := &config.LanguageConfig{
+			Name:            "ru",
+			BaseURL:         server.URL + "/ru/",
+			PageURLTemplate: "{base}page/{n}/",
+			StartPage:       tt.startPage,
+			MaxPages:        10,
+		}
+
+		stats, err := o.Run(context.Background(), langCfg)
+		if err != nil {
+			t.Fatalf("%s: Run error: %v", tt.name, err)
+		}
+		if stats.StoppedReason != tt.wantReason {
+			t.Errorf("%s: StoppedReason = %q, want %q", tt.name, stats.StoppedReason, tt.wantReason)
+		}
+		if stats.NewCards != tt.wantCards || stats.TotalPages != len(tt.wantPaths)-1 {
+			t.Errorf("%s: new cards = %d, pages = %d; want %d, %d", tt.name, stats.NewCards, stats.TotalPages, tt.wantCards, len(tt.wantPaths)-1)
+		}
+		if strings.Join(requested, " ") != strings.Join(tt.wantPaths, " ") {
+			t.Errorf("%s: requested %v, want %v", tt.name, requested, tt.wantPaths)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// RunParallel вызывает fn для каждого элемента items, не более workers одновременно.
+// После отмены ctx новые элементы не запускаются, уже запущенные дорабатывают;
+// функция возвращается только после их завершения.
+// Ошибки всех элементов (и ctx.Err() при отмене) объединяются через errors.Join
+func RunParallel[T any](ctx context.Context, workers int, items []T, fn func(item T) error) error {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	sem := make(chan struct{}, workers)
+
+dispatch:
+	for _, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+
+		// Слот мог освободиться одновременно с отменой — проверяем ctx ещё раз
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(item); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
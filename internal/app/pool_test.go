@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunParallel(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		workers int
+		items   []int
+		failOn  int
+		wantErr bool
+	}{
+		{name: "sequential", workers: 1, items: []int{1, 2, 3}},
+		{name: "parallel", workers: 3, items: []int{1, 2, 3, 4, 5}},
+		{name: "zero workers", workers: 0, items: []int{1, 2}},
+		{name: "error does not stop others", workers: 2, items: []int{1, 2, 3}, failOn: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var running, maxRunning, done atomic.Int32
+		err := RunParallel(context.Background(), tt.workers, tt.items, func(item int) error {
+			current := running.Add(1)
+			for {
+				peak := maxRunning.Load()
+				if current <= peak || maxRunning.CompareAndSwap(peak, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			done.Add(1)
+
+			if item == tt.failOn {
+				return errFailed
+			}
+			return nil
+		})
+
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: RunParallel error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if tt.wantErr && !errors.Is(err, errFailed) {
+			t.Errorf("%s: RunParallel error = %v, want errFailed", tt.name, err)
+		}
+		if int(done.Load()) != len(tt.items) {
+			t.Errorf("%s: processed %d items, want %d", tt.name, done.Load(), len(tt.items))
+		}
+		limit := max(tt.workers, 1)
+		if int(maxRunning.Load()) > limit {
+			t.Errorf("%s: %d items ran concurrently, limit %d", tt.name, maxRunning.Load(), limit)
+		}
+	}
+}
+
+func TestRunParallelCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var started atomic.Int32
+	err := RunParallel(ctx, 1, []int{1, 2, 3}, func(item int) error {
+		started.Add(1)
+		cancel()
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RunParallel error = %v, want context.Canceled", err)
+	}
+	if started.Load() != 1 {
+		t.Errorf("started %d items after cancel, want 1", started.Load())
+	}
+}
@@ -10,10 +10,13 @@ import (
 	"oshcity-news-parser/internal/observability"
 )
 
-// GracefulShutdown запускает мониторинг OS сигналов и возвращает context для отмены
-func GracefulShutdown(logger *observability.Logger, shutdownTimeout time.Duration) (context.Context, context.CancelFunc) {
-	// Создаём context с таймаутом для graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+// GracefulShutdown запускает мониторинг OS сигналов и возвращает context для отмены.
+// Context отменяется при получении сигнала; если после сигнала работа не завершилась
+// за shutdownTimeout, закрывается канал forced — вызывающий перестаёт её ждать и выходит
+// через обычный возврат, чтобы отложенные закрытия ресурсов (БД, браузер, метрики) выполнились
+func GracefulShutdown(logger *observability.Logger, shutdownTimeout time.Duration) (context.Context, context.CancelFunc, <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	forced := make(chan struct{})
 
 	// Канал для сигналов ОС
 	sigChan := make(chan os.Signal, 1)
@@ -23,9 +26,31 @@ func GracefulShutdown(logger *observability.Logger, shutdownTimeout time.Duratio
 		sig := <-sigChan
 		logger.Info("Shutdown signal received", "signal", sig.String())
 		cancel() // Отменяем context при получении сигнала
+
+		// Ограничиваем время на завершение текущей работы
+		time.AfterFunc(shutdownTimeout, func() {
+			logger.Error("Graceful shutdown timeout exceeded, forcing exit", "timeout", shutdownTimeout.String())
+			close(forced)
+		})
+	}()
+
+	return ctx, cancel, forced
+}
+
+// UntilForced выполняет fn и ждёт его завершения, пока не закрыт forced (см. GracefulShutdown).
+// finished = false — ожидание прервано принудительным завершением, fn ещё выполняется
+func UntilForced(forced <-chan struct{}, fn func() error) (finished bool, err error) {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
 	}()
 
-	return ctx, cancel
+	select {
+	case err := <-done:
+		return true, err
+	case <-forced:
+		return false, nil
+	}
 }
 
 /*
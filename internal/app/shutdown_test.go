@@ -0,0 +1,55 @@
+package app
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"oshcity-news-parser/internal/observability"
+)
+
+func TestGracefulShutdownForcedAfterTimeout(t *testing.T) {
+	logger := observability.NewLogger("", "error", 0, 0, 0)
+	ctx, cancel, forced := GracefulShutdown(logger, 50*time.Millisecond)
+	defer cancel()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("send SIGTERM: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("context not cancelled after signal")
+	}
+
+	// Процесс не завершается принудительно: закрывается канал, и main выходит сам
+	select {
+	case <-forced:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("forced channel not closed after shutdown timeout")
+	}
+}
+
+func TestUntilForced(t *testing.T) {
+	failure := errors.New("scheduler failed")
+
+	finished, err := UntilForced(make(chan struct{}), func() error { return failure })
+	if !finished || !errors.Is(err, failure) {
+		t.Errorf("UntilForced = %v, %v; want true, %v", finished, err, failure)
+	}
+
+	// Зависшая работа не держит выход после принудительного завершения
+	forced := make(chan struct{})
+	close(forced)
+	block := make(chan struct{})
+	defer close(block)
+	finished, err = UntilForced(forced, func() error {
+		<-block
+		return nil
+	})
+	if finished || err != nil {
+		t.Errorf("UntilForced = %v, %v; want false, nil", finished, err)
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"oshcity-news-parser/internal/config"
+)
+
+// runWPAPI — получение новостей через WordPress REST API.
+// Параметр after отсекает уже известные посты, поэтому серия "старых" страниц не отслеживается.
+func (o *Orchestrator) runWPAPI(ctx context.Context, langCfg *config.LanguageConfig, latestKnownDate time.Time) (*PaginationStats, error) {
+	o.logger.Info("Starting WP API pagination",
+		"language", langCfg.Name,
+		"endpoint", langCfg.WPAPI.Endpoint,
+		"max_pages", langCfg.MaxPages,
+		"latest_known_date", latestKnownDate.Format("2006-01-02"),
+	)
+
+	stats := &PaginationStats{}
+
+	for pageNum := 1; pageNum <= langCfg.MaxPages; pageNum++ {
+		// Проверяем context на отмену
+		select {
+		case <-ctx.Done():
+			o.logger.Info("Context cancelled, stopping WP API pagination",
+				"language", langCfg.Name,
+				"page", pageNum,
+			)
+			stats.StoppedReason = "shutdown signal received"
+			return stats, ctx.Err()
+		default:
+		}
+
+		page, err := o.wpClient.FetchPage(ctx, langCfg, pageNum, latestKnownDate)
+		if err != nil {
+			o.logger.Error("WP API fetch failed",
+				"language", langCfg.Name,
+				"page", pageNum,
+				"error", err.Error(),
+			)
+			stats.StoppedReason = fmt.Sprintf("wp api error at page %d: %v", pageNum, err)
+			return stats, err
+		}
+
+		if len(page.Cards) == 0 {
+			o.logger.Info("No posts on WP API page",
+				"language", langCfg.Name,
+				"page", pageNum,
+			)
+			stats.StoppedReason = fmt.Sprintf("no posts on page %d", pageNum)
+			break
+		}
+
+		stats.TotalPages++
+		stats.TotalCards += len(page.Cards)
+
+		oldCardsOnPage, _, err := o.processCards(ctx, langCfg, page.Cards, pageNum, latestKnownDate)
+		if err != nil {
+			stats.StoppedReason = fmt.Sprintf("storage error at page %d: %v", pageNum, err)
+			return stats, err
+		}
+		stats.OldCards += oldCardsOnPage
+
+		o.logger.Info("WP API page analysis",
+			"language", langCfg.Name,
+			"page", pageNum,
+			"total_cards", len(page.Cards),
+			"old_cards", oldCardsOnPage,
+			"total_pages", page.TotalPages,
+		)
+
+		if page.TotalPages > 0 && pageNum >= page.TotalPages {
+			stats.StoppedReason = fmt.Sprintf("last WP API page %d", pageNum)
+			break
+		}
+	}
+
+	o.logger.Info("WP API pagination completed",
+		"language", langCfg.Name,
+		"total_pages", stats.TotalPages,
+		"total_cards", stats.TotalCards,
+		"old_cards", stats.OldCards,
+		"reason", stats.StoppedReason,
+	)
+
+	return stats, nil
+}
@@ -2,54 +2,69 @@ package checksum
 
 import (
 	"testing"
-	"time"
 )
 
 func TestGenerateContentHash(t *testing.T) {
 	gen := NewGenerator()
 
-	url := "https://example.com/news/123"
+	sequenceNum := 1
+	date := "2025-10-18"
 	title := "Тестовая новость"
 	text := "Содержание новости"
-	date := time.Date(2025, 10, 18, 0, 0, 0, 0, time.UTC)
+	image := []byte{0xFF, 0xD8, 0xFF, 0xE0}
 
-	hash1 := gen.GenerateContentHash(url, title, text, date)
-	hash2 := gen.GenerateContentHash(url, title, text, date)
+	hash1 := gen.GenerateContentHash(sequenceNum, date, title, text, image)
+	hash2 := gen.GenerateContentHash(sequenceNum, date, title, text, image)
 
 	// Хеш должен быть детерминированным
 	if hash1 != hash2 {
 		t.Errorf("Hash not deterministic: %s != %s", hash1, hash2)
 	}
 
-	// Хеш должен быть 64 символа (SHA256 hex)
-	if len(hash1) != 64 {
-		t.Errorf("Hash wrong length: %d, expected 64", len(hash1))
+	// Хеш должен быть 320 символов (5 x SHA256 hex)
+	if len(hash1) != 320 {
+		t.Errorf("Hash wrong length: %d, expected 320", len(hash1))
 	}
 
 	// Изменение контента должно изменить хеш
-	hash3 := gen.GenerateContentHash(url, "Другой заголовок", text, date)
+	hash3 := gen.GenerateContentHash(sequenceNum, date, "Другой заголовок", text, image)
 	if hash1 == hash3 {
 		t.Errorf("Hash should change when title changes")
 	}
+
+	// Замена изображения должна изменить только последний сегмент
+	hash4 := gen.GenerateContentHash(sequenceNum, date, title, text, []byte{0x89, 0x50, 0x4E, 0x47})
+	if hash1[:256] != hash4[:256] {
+		t.Errorf("Image change should not affect first 4 segments")
+	}
+	if hash1[256:] == hash4[256:] {
+		t.Errorf("Hash should change when image bytes change")
+	}
 }
 
 func TestVerifyContentHash(t *testing.T) {
 	gen := NewGenerator()
 
-	url := "https://example.com/news/123"
+	sequenceNum := 1
+	date := "2025-10-18"
 	title := "Тестовая новость"
 	text := "Содержание новости"
-	date := time.Date(2025, 10, 18, 0, 0, 0, 0, time.UTC)
+	image := []byte{0xFF, 0xD8, 0xFF, 0xE0}
 
-	hash := gen.GenerateContentHash(url, title, text, date)
+	hash := gen.GenerateContentHash(sequenceNum, date, title, text, image)
 
 	// Проверка с правильными данными
-	if !gen.VerifyContentHash(hash, url, title, text, date) {
+	if !gen.VerifyContentHash(hash, sequenceNum, date, title, text, image) {
 		t.Errorf("VerifyContentHash failed for correct data")
 	}
 
 	// Проверка с неправильным заголовком
-	if gen.VerifyContentHash(hash, url, "Другой заголовок", text, date) {
+	if gen.VerifyContentHash(hash, sequenceNum, date, "Другой заголовок", text, image) {
 		t.Errorf("VerifyContentHash should fail for wrong title")
 	}
+
+	// Проверка с другим изображением
+	if gen.VerifyContentHash(hash, sequenceNum, date, title, text, []byte{}) {
+		t.Errorf("VerifyContentHash should fail for wrong image bytes")
+	}
 }
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"crypto/x509"
 	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,10 +16,16 @@ type Config struct {
 	Backoff             BackoffConfig       `yaml:"backoff"`
 	RobotsCacheTTLHours int                 `yaml:"robots_cache_ttl_hours"`
 	HTTP                HttpConfig          `yaml:"http"`
+	HTTPCache           HTTPCacheConfig     `yaml:"http_cache"`
+	Proxy               ProxyConfig         `yaml:"proxy"`
+	Archive             ArchiveConfig       `yaml:"archive"`
 	RateLimit           RateLimitConfig     `yaml:"rate_limit"`
 	Pagination          PaginationConfig    `yaml:"pagination"`
+	Backfill            BackfillConfig      `yaml:"backfill"`
 	SelectorsFile       SelectorsFileConfig `yaml:"selectors_file"`
 	Normalize           NormalizeConfig     `yaml:"normalize"`
+	Detail              DetailConfig        `yaml:"detail"`
+	Images              ImagesConfig        `yaml:"images"`
 	Storage             StorageConfig       `yaml:"storage"`
 	Scheduler           SchedulerConfig     `yaml:"scheduler"`
 	Observability       ObservabilityConfig `yaml:"observability"`
@@ -27,14 +38,34 @@ type LanguageConfig struct {
 	AcceptLanguage string `yaml:"accept_language"`
 	MaxPages       int    `yaml:"max_pages"`
 	TimeoutSeconds int    `yaml:"timeout_seconds"`
+	FeedURL        string `yaml:"feed_url"` // RSS/Atom фид (pagination.strategy: feed)
+
+	// Пагинация по номеру страницы (pagination.strategy: template)
+	PageURLTemplate string `yaml:"page_url_template"` // например "{base}page/{n}/"; {base} — base_url, {n} — номер страницы
+	StartPage       int    `yaml:"start_page"`        // страница, с которой начинать обход (0 — первая)
+
+	SitemapURLPrefix string `yaml:"sitemap_url_prefix"` // backfill: префикс URL статей языка в sitemap (пусто — base_url)
+
+	WPAPI WPAPIConfig `yaml:"wp_api"`
+}
+
+// WPAPIConfig — источник WordPress REST API (/wp-json/wp/v2/posts) вместо HTML-скрапинга
+type WPAPIConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Endpoint   string `yaml:"endpoint"`
+	PerPage    int    `yaml:"per_page"`
+	Lang       string `yaml:"lang"`       // параметр lang (Polylang/WPML), пусто — не передаётся
+	Categories string `yaml:"categories"` // ID рубрик через запятую, пусто — все
 }
 
 type RodConfig struct {
 	Enabled          bool   `yaml:"enabled"`
+	Backend          string `yaml:"backend"` // http | browser | auto (пусто — browser, если enabled)
 	ChromePath       string `yaml:"chrome_path"`
 	PageTimeoutS     int    `yaml:"page_timeout_s"`
 	WaitLoadTimeoutS int    `yaml:"wait_load_timeout_s"`
-	LazyLoadDelayS   int    `yaml:"lazy_load_delay_s"`
+	LazyLoadDelayS   int    `yaml:"lazy_load_delay_s"` // Максимальное ожидание lazy-load после готовности страницы
+	MaxPages         int    `yaml:"max_pages"`         // Размер пула вкладок (0 — rate_limit.max_concurrent_per_host)
 }
 
 type BackoffConfig struct {
@@ -51,11 +82,36 @@ type HttpConfig struct {
 	MaxIdleConnections        int    `yaml:"max_idle_connections"`
 	MaxIdleConnectionsPerHost int    `yaml:"max_idle_connections_per_host"`
 	IdleConnectionTimeoutS    int    `yaml:"idle_connection_timeout_s"`
+	CABundle                  string `yaml:"ca_bundle"` // PEM-файл с дополнительными корневыми сертификатами
+}
+
+// ProxyConfig — исходящие прокси для HTTP-клиента и браузера Rod
+type ProxyConfig struct {
+	URLs             []string `yaml:"urls"`              // http://, https://, socks5:// (с user:password при необходимости)
+	FailureThreshold int      `yaml:"failure_threshold"` // Ошибок подряд, после которых прокси выводится из ротации
+	CooldownS        int      `yaml:"cooldown_s"`        // Время вне ротации
+}
+
+type HTTPCacheConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir"`
+}
+
+type ArchiveConfig struct {
+	Mode string `yaml:"mode"`
+	Dir  string `yaml:"dir"`
+}
+
+// BackfillConfig — догрузка полного архива новостей по sitemap.xml (режим --backfill)
+type BackfillConfig struct {
+	Sitemaps []string `yaml:"sitemaps"` // корневые sitemap; пусто — из robots.txt и /sitemap.xml
+	MaxURLs  int      `yaml:"max_urls"` // лимит загружаемых статей на язык (0 — без лимита)
 }
 
 type RateLimitConfig struct {
 	MaxConcurrentPerHost int `yaml:"max_concurrent_per_host"`
 	RPM                  int `yaml:"rpm"`
+	Burst                int `yaml:"burst"` // Ёмкость token bucket (0 — rpm)
 }
 
 type PaginationConfig struct {
@@ -78,6 +134,17 @@ type NormalizeConfig struct {
 	MaxPreviewChars int      `yaml:"max_preview_chars"`
 }
 
+type DetailConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type ImagesConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	CacheDir  string `yaml:"cache_dir"`
+	StoreInDB bool   `yaml:"store_in_db"`
+	MaxSizeKB int    `yaml:"max_size_kb"`
+}
+
 type StorageConfig struct {
 	Driver                string `yaml:"driver"`
 	DSN                   string `yaml:"dsn"`
@@ -95,15 +162,19 @@ type SchedulerConfig struct {
 	IntervalS                int    `yaml:"interval_s"`
 	CronExpr                 string `yaml:"cron_expr"`
 	GracefulShutdownTimeoutS int    `yaml:"graceful_shutdown_timeout_s"`
+	Workers                  int    `yaml:"workers"` // Языков, обрабатываемых параллельно (0 или 1 — последовательно)
 }
 
 type ObservabilityConfig struct {
-	LogPath       string `yaml:"log_path"`
-	LogLevel      string `yaml:"log_level"`
-	MetricsPath   string `yaml:"metrics_path"`
-	MaxLogAgeDays int    `yaml:"max_log_age_days"`
-	MaxLogSizeMB  int    `yaml:"max_log_size_mb"`
-	MaxBackups    int    `yaml:"max_backups"`
+	LogPath              string `yaml:"log_path"`
+	LogLevel             string `yaml:"log_level"`
+	MetricsPath          string `yaml:"metrics_path"`
+	MetricsListen        string `yaml:"metrics_listen"`          // адрес listener с /metrics (например ":9090"), пусто — отключён
+	MetricsDumpIntervalS int    `yaml:"metrics_dump_interval_s"` // период записи метрик в metrics_path (0 — 60 секунд)
+	HealthListen         string `yaml:"health_listen"`           // адрес listener с /healthz, /readyz, /status; пусто — отключён
+	MaxLogAgeDays        int    `yaml:"max_log_age_days"`
+	MaxLogSizeMB         int    `yaml:"max_log_size_mb"`
+	MaxBackups           int    `yaml:"max_backups"`
 }
 
 // Validation
@@ -135,6 +206,14 @@ func (c *Config) Validate() error {
 		if lang.MaxPages <= 0 {
 			return fmt.Errorf("languages[%d].max_pages must be > 0", i)
 		}
+		if lang.WPAPI.Enabled {
+			if lang.WPAPI.Endpoint == "" {
+				return fmt.Errorf("languages[%d].wp_api.endpoint is required when wp_api.enabled is true", i)
+			}
+			if lang.WPAPI.PerPage <= 0 || lang.WPAPI.PerPage > 100 {
+				return fmt.Errorf("languages[%d].wp_api.per_page must be in 1..100", i)
+			}
+		}
 	}
 
 	// Валидация HTTP
@@ -150,6 +229,63 @@ func (c *Config) Validate() error {
 	if c.HTTP.MaxRetries < 0 {
 		return fmt.Errorf("http.max_retries must be >= 0")
 	}
+	if c.HTTP.CABundle != "" {
+		pem, err := os.ReadFile(c.HTTP.CABundle)
+		if err != nil {
+			return fmt.Errorf("http.ca_bundle is not readable: %w", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+			return fmt.Errorf("http.ca_bundle: no PEM certificates found in %s", c.HTTP.CABundle)
+		}
+	}
+
+	// Валидация Proxy
+	// URL прокси в ошибках не выводится — в нём могут быть учётные данные
+	for i, rawURL := range c.Proxy.URLs {
+		proxyURL, err := url.Parse(rawURL)
+		if err != nil || proxyURL.Host == "" {
+			return fmt.Errorf("proxy.urls[%d]: invalid proxy URL", i)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("proxy.urls[%d]: unsupported proxy scheme %q (want http, https or socks5)", i, proxyURL.Scheme)
+		}
+	}
+	if c.Proxy.FailureThreshold < 0 {
+		return fmt.Errorf("proxy.failure_threshold must be >= 0")
+	}
+	if c.Proxy.CooldownS < 0 {
+		return fmt.Errorf("proxy.cooldown_s must be >= 0")
+	}
+
+	if c.RobotsCacheTTLHours < 0 {
+		return fmt.Errorf("robots_cache_ttl_hours must be >= 0")
+	}
+
+	// Валидация HTTPCache
+	if c.HTTPCache.Enabled && c.HTTPCache.Dir == "" {
+		return fmt.Errorf("http_cache.dir is required when http_cache.enabled is true")
+	}
+
+	// Валидация Archive
+	if c.Archive.Mode != "" && c.Archive.Mode != "off" && c.Archive.Mode != "record" && c.Archive.Mode != "replay" {
+		return fmt.Errorf("archive.mode must be 'off', 'record' or 'replay'")
+	}
+	if (c.Archive.Mode == "record" || c.Archive.Mode == "replay") && c.Archive.Dir == "" {
+		return fmt.Errorf("archive.dir is required when archive.mode is '%s'", c.Archive.Mode)
+	}
+
+	// Валидация Rod
+	switch c.Rod.Backend {
+	case "", "http":
+	case "browser", "auto":
+		if !c.Rod.Enabled {
+			return fmt.Errorf("rod.backend '%s' requires rod.enabled: true", c.Rod.Backend)
+		}
+	default:
+		return fmt.Errorf("rod.backend must be 'http', 'browser' or 'auto'")
+	}
 
 	// Валидация RateLimit
 	if c.RateLimit.MaxConcurrentPerHost <= 0 {
@@ -158,8 +294,34 @@ func (c *Config) Validate() error {
 	if c.RateLimit.RPM <= 0 {
 		return fmt.Errorf("rate_limit.rpm must be > 0")
 	}
+	if c.RateLimit.Burst < 0 {
+		return fmt.Errorf("rate_limit.burst must be >= 0")
+	}
 
 	// Валидация Pagination
+	if c.Pagination.Strategy != "" && c.Pagination.Strategy != "links" && c.Pagination.Strategy != "feed" && c.Pagination.Strategy != "template" {
+		return fmt.Errorf("pagination.strategy must be 'links', 'feed' or 'template'")
+	}
+	if c.Pagination.Strategy == "feed" {
+		for i, lang := range c.Languages {
+			if lang.FeedURL == "" && !lang.WPAPI.Enabled {
+				return fmt.Errorf("languages[%d].feed_url is required when pagination.strategy is 'feed'", i)
+			}
+		}
+	}
+	if c.Pagination.Strategy == "template" {
+		for i, lang := range c.Languages {
+			if lang.WPAPI.Enabled {
+				continue
+			}
+			if !strings.Contains(lang.PageURLTemplate, "{n}") {
+				return fmt.Errorf("languages[%d].page_url_template must contain {n} when pagination.strategy is 'template'", i)
+			}
+			if lang.StartPage < 0 {
+				return fmt.Errorf("languages[%d].start_page must be >= 0", i)
+			}
+		}
+	}
 	if c.Pagination.StopOnKnownChainPages <= 0 {
 		return fmt.Errorf("pagination.stop_on_known_chain_pages must be > 0")
 	}
@@ -167,9 +329,26 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("pagination.days_back_threshold must be >= 0")
 	}
 
+	// Валидация Backfill
+	if c.Backfill.MaxURLs < 0 {
+		return fmt.Errorf("backfill.max_urls must be >= 0")
+	}
+
+	// Валидация Images
+	if c.Images.Enabled {
+		if c.Images.CacheDir == "" {
+			return fmt.Errorf("images.cache_dir is required when images.enabled is true")
+		}
+		if c.Images.MaxSizeKB <= 0 {
+			return fmt.Errorf("images.max_size_kb must be > 0")
+		}
+	}
+
 	// Валидация Storage
-	if c.Storage.Driver == "" || (c.Storage.Driver != "mssql" && c.Storage.Driver != "postgres") {
-		return fmt.Errorf("storage.driver must be 'mssql' or 'postgres'")
+	switch c.Storage.Driver {
+	case "mssql", "postgres", "sqlite":
+	default:
+		return fmt.Errorf("storage.driver must be 'mssql', 'postgres' or 'sqlite'")
 	}
 	if c.Storage.DSN == "" {
 		return fmt.Errorf("storage.dsn is required")
@@ -191,6 +370,9 @@ func (c *Config) Validate() error {
 	if c.Scheduler.Mode == "cron" && c.Scheduler.CronExpr == "" {
 		return fmt.Errorf("scheduler.cron_expr must be set when mode is 'cron'")
 	}
+	if c.Scheduler.Workers < 0 {
+		return fmt.Errorf("scheduler.workers must be >= 0")
+	}
 
 	// Валидация Observability
 	if c.Observability.LogPath == "" {
@@ -232,10 +414,49 @@ func (c *Config) GetSchedulerInterval() time.Duration {
 	return time.Duration(c.Scheduler.IntervalS) * time.Second
 }
 
+func (c *Config) GetSchedulerWorkers() int {
+	if c.Scheduler.Workers <= 0 {
+		return 1
+	}
+	return c.Scheduler.Workers
+}
+
 func (c *Config) GetRobotsCacheTTL() time.Duration {
 	return time.Duration(c.RobotsCacheTTLHours) * time.Hour
 }
 
+func (c *Config) GetMetricsDumpInterval() time.Duration {
+	if c.Observability.MetricsDumpIntervalS <= 0 {
+		return time.Minute
+	}
+	return time.Duration(c.Observability.MetricsDumpIntervalS) * time.Second
+}
+
+// GetFetchBackend возвращает backend загрузки страниц с учётом rod.enabled
+func (c *Config) GetFetchBackend() string {
+	if !c.Rod.Enabled {
+		return "http"
+	}
+	if c.Rod.Backend == "" {
+		return "browser"
+	}
+	return c.Rod.Backend
+}
+
+func (c *Config) GetProxyFailureThreshold() int {
+	if c.Proxy.FailureThreshold <= 0 {
+		return 3
+	}
+	return c.Proxy.FailureThreshold
+}
+
+func (c *Config) GetProxyCooldown() time.Duration {
+	if c.Proxy.CooldownS <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(c.Proxy.CooldownS) * time.Second
+}
+
 func (c *Config) GetRodPageTimeout() time.Duration {
 	return time.Duration(c.Rod.PageTimeoutS) * time.Second
 }
@@ -247,3 +468,20 @@ func (c *Config) GetRodWaitLoadTimeout() time.Duration {
 func (c *Config) GetRodLazyLoadDelay() time.Duration {
 	return time.Duration(c.Rod.LazyLoadDelayS) * time.Second
 }
+
+// PageURL возвращает URL страницы n по page_url_template; первая страница — base_url
+func (l *LanguageConfig) PageURL(n int) string {
+	if n <= 1 {
+		return l.BaseURL
+	}
+	pageURL := strings.ReplaceAll(l.PageURLTemplate, "{base}", l.BaseURL)
+	return strings.ReplaceAll(pageURL, "{n}", strconv.Itoa(n))
+}
+
+// BackfillURLPrefix возвращает префикс URL статей языка для отбора из sitemap
+func (l *LanguageConfig) BackfillURLPrefix() string {
+	if l.SitemapURLPrefix != "" {
+		return l.SitemapURLPrefix
+	}
+	return l.BaseURL
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadTestConfig загружает configs/config.yaml — валидную конфигурацию для изменения в тестах
+func loadTestConfig(t *testing.T) *Config {
+	t.Helper()
+
+	cfg, err := LoadConfig("../../configs/config.yaml")
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	return cfg
+}
+
+func TestValidateCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(nil)
+	server.Close()
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	dir := t.TempDir()
+	writeFile := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("WriteFile error: %v", err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "valid bundle", path: writeFile("ca.pem", certPEM)},
+		{name: "missing file", path: filepath.Join(dir, "missing.pem"), wantErr: true},
+		{name: "not PEM", path: writeFile("garbage.pem", []byte("not a certificate")), wantErr: true},
+		{name: "empty file", path: writeFile("empty.pem", nil), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		cfg := loadTestConfig(t)
+		cfg.HTTP.CABundle = tt.path
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPageURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		n        int
+		want     string
+	}{
+		{name: "first page is base_url", template: "{base}page/{n}/", n: 1, want: "https://oshcity.gov.kg/ru/novosti/"},
+		{name: "page below first", template: "{base}page/{n}/", n: 0, want: "https://oshcity.gov.kg/ru/novosti/"},
+		{name: "path template", template: "{base}page/{n}/", n: 2, want: "https://oshcity.gov.kg/ru/novosti/page/2/"},
+		{name: "query template", template: "{base}?paged={n}", n: 12, want: "https://oshcity.gov.kg/ru/novosti/?paged=12"},
+		{name: "absolute template", template: "https://oshcity.gov.kg/ru/archive/{n}", n: 3, want: "https://oshcity.gov.kg/ru/archive/3"},
+		{name: "repeated placeholder", template: "{base}{n}/?p={n}", n: 4, want: "https://oshcity.gov.kg/ru/novosti/4/?p=4"},
+	}
+
+	for _, tt := range tests {
+		lang := &LanguageConfig{BaseURL: "https://oshcity.gov.kg/ru/novosti/", PageURLTemplate: tt.template}
+		if got := lang.PageURL(tt.n); got != tt.want {
+			t.Errorf("%s: PageURL(%d) = %q, want %q", tt.name, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"oshcity-news-parser/internal/normalize"
+	"oshcity-news-parser/internal/scraper"
+)
+
+// rssFeed — RSS 2.0
+type rssFeed struct {
+	XMLName xml.Name  `xml:"rss"`
+	Items   []rssItem `xml:"channel>item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	PubDate     string `xml:"pubDate"`
+	Description string `xml:"description"`
+	Enclosure   []struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+	MediaContent []struct {
+		URL    string `xml:"url,attr"`
+		Medium string `xml:"medium,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaThumbnail []struct {
+		URL string `xml:"url,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+// atomFeed — Atom 1.0
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// rssDateLayouts — форматы pubDate, встречающиеся на практике
+var rssDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC3339,
+}
+
+// Parse разбирает RSS или Atom фид и возвращает карточки в порядке фида
+func Parse(body []byte) ([]*scraper.Card, error) {
+	root, err := rootElement(body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss":
+		var rss rssFeed
+		if err := newDecoder(body).Decode(&rss); err != nil {
+			return nil, fmt.Errorf("failed to parse RSS: %w", err)
+		}
+		return rssToCards(rss.Items), nil
+	case "feed":
+		var atom atomFeed
+		if err := newDecoder(body).Decode(&atom); err != nil {
+			return nil, fmt.Errorf("failed to parse Atom: %w", err)
+		}
+		return atomToCards(atom.Entries), nil
+	default:
+		return nil, fmt.Errorf("unsupported feed root element: %s", root)
+	}
+}
+
+func rssToCards(items []rssItem) []*scraper.Card {
+	cards := make([]*scraper.Card, 0, len(items))
+	for i, item := range items {
+		card := &scraper.Card{
+			Title:       normalize.HTMLToText(item.Title),
+			URL:         normalize.NormalizeURL(item.Link),
+			Text:        normalize.HTMLToText(item.Description),
+			DateRaw:     strings.TrimSpace(item.PubDate),
+			SequenceNum: i + 1,
+		}
+
+		// Изображение: enclosure image/* → media:content → media:thumbnail
+		for _, enclosure := range item.Enclosure {
+			if strings.HasPrefix(enclosure.Type, "image/") {
+				card.ThumbnailURL = enclosure.URL
+				break
+			}
+		}
+		if card.ThumbnailURL == "" {
+			for _, media := range item.MediaContent {
+				if media.Medium == "" || media.Medium == "image" {
+					card.ThumbnailURL = media.URL
+					break
+				}
+			}
+		}
+		if card.ThumbnailURL == "" && len(item.MediaThumbnail) > 0 {
+			card.ThumbnailURL = item.MediaThumbnail[0].URL
+		}
+		card.ThumbnailURL = normalize.NormalizeURL(card.ThumbnailURL)
+
+		card.PublishedAt = parseDay(card.DateRaw, rssDateLayouts)
+		if card.Text == "" {
+			card.Text = card.Title
+		}
+
+		cards = append(cards, card)
+	}
+	return cards
+}
+
+func atomToCards(entries []atomEntry) []*scraper.Card {
+	cards := make([]*scraper.Card, 0, len(entries))
+	for i, entry := range entries {
+		card := &scraper.Card{
+			Title:       normalize.HTMLToText(entry.Title),
+			Text:        normalize.HTMLToText(entry.Summary),
+			DateRaw:     strings.TrimSpace(entry.Published),
+			SequenceNum: i + 1,
+		}
+
+		for _, link := range entry.Links {
+			switch {
+			case (link.Rel == "" || link.Rel == "alternate") && card.URL == "":
+				card.URL = normalize.NormalizeURL(link.Href)
+			case link.Rel == "enclosure" && strings.HasPrefix(link.Type, "image/") && card.ThumbnailURL == "":
+				card.ThumbnailURL = normalize.NormalizeURL(link.Href)
+			}
+		}
+
+		if card.DateRaw == "" {
+			card.DateRaw = strings.TrimSpace(entry.Updated)
+		}
+		card.PublishedAt = parseDay(card.DateRaw, []string{time.RFC3339})
+
+		if card.Text == "" {
+			card.Text = normalize.HTMLToText(entry.Content)
+		}
+		if card.Text == "" {
+			card.Text = card.Title
+		}
+
+		cards = append(cards, card)
+	}
+	return cards
+}
+
+// parseDay парсит дату и возвращает её день (00:00 UTC), как у дат листинга; zero — не удалось
+func parseDay(value string, layouts []string) time.Time {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		}
+	}
+	return time.Time{}
+}
+
+// rootElement возвращает имя корневого элемента XML
+func rootElement(body []byte) (string, error) {
+	decoder := newDecoder(body)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to read feed: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func newDecoder(body []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	return decoder
+}
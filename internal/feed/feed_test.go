@@ -0,0 +1,109 @@
+package feed
+
+import (
+	"testing"
+	"time"
+)
+
+const testRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/">
+<channel>
+	<title>Ош шаары</title>
+	<item>
+		<title>Мэрия &#8211; новости</title>
+		<link>https://oshcity.gov.kg/ru/novosti/test-news/#comments</link>
+		<pubDate>Sat, 18 Oct 2025 14:30:00 +0000</pubDate>
+		<description><![CDATA[<p>Краткое  описание&hellip;</p>]]></description>
+		<enclosure url="https://oshcity.gov.kg/wp-content/uploads/photo.jpg" type="image/jpeg" length="1000"/>
+	</item>
+	<item>
+		<title>Без описания</title>
+		<link>https://oshcity.gov.kg/ru/novosti/other/</link>
+		<pubDate>Fri, 17 Oct 2025 09:00:00 +0600</pubDate>
+		<media:thumbnail url="https://oshcity.gov.kg/wp-content/uploads/thumb.jpg"/>
+	</item>
+</channel>
+</rss>`
+
+const testAtom = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Ош шаары</title>
+	<entry>
+		<title>Atom новость</title>
+		<link rel="alternate" href="https://oshcity.gov.kg/ru/novosti/atom-news/"/>
+		<link rel="enclosure" type="image/png" href="https://oshcity.gov.kg/wp-content/uploads/atom.png"/>
+		<published>2025-10-16T08:00:00+06:00</published>
+		<summary>Текст записи</summary>
+	</entry>
+</feed>`
+
+func TestParseRSS(t *testing.T) {
+	cards, err := Parse([]byte(testRSS))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(cards) != 2 {
+		t.Fatalf("len(cards) = %d, want 2", len(cards))
+	}
+
+	first := cards[0]
+	if first.Title != "Мэрия – новости" {
+		t.Errorf("Title = %q", first.Title)
+	}
+	if first.URL != "https://oshcity.gov.kg/ru/novosti/test-news/" {
+		t.Errorf("URL = %q", first.URL)
+	}
+	if first.Text != "Краткое описание…" {
+		t.Errorf("Text = %q", first.Text)
+	}
+	if first.ThumbnailURL != "https://oshcity.gov.kg/wp-content/uploads/photo.jpg" {
+		t.Errorf("ThumbnailURL = %q", first.ThumbnailURL)
+	}
+	if want := time.Date(2025, 10, 18, 0, 0, 0, 0, time.UTC); !first.PublishedAt.Equal(want) {
+		t.Errorf("PublishedAt = %v, want %v", first.PublishedAt, want)
+	}
+	if first.SequenceNum != 1 {
+		t.Errorf("SequenceNum = %d, want 1", first.SequenceNum)
+	}
+
+	second := cards[1]
+	if second.Text != second.Title {
+		t.Errorf("Text = %q, want title fallback", second.Text)
+	}
+	if second.ThumbnailURL != "https://oshcity.gov.kg/wp-content/uploads/thumb.jpg" {
+		t.Errorf("ThumbnailURL = %q", second.ThumbnailURL)
+	}
+	if want := time.Date(2025, 10, 17, 0, 0, 0, 0, time.UTC); !second.PublishedAt.Equal(want) {
+		t.Errorf("PublishedAt = %v, want %v", second.PublishedAt, want)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	cards, err := Parse([]byte(testAtom))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(cards) != 1 {
+		t.Fatalf("len(cards) = %d, want 1", len(cards))
+	}
+
+	card := cards[0]
+	if card.URL != "https://oshcity.gov.kg/ru/novosti/atom-news/" {
+		t.Errorf("URL = %q", card.URL)
+	}
+	if card.ThumbnailURL != "https://oshcity.gov.kg/wp-content/uploads/atom.png" {
+		t.Errorf("ThumbnailURL = %q", card.ThumbnailURL)
+	}
+	if card.Text != "Текст записи" {
+		t.Errorf("Text = %q", card.Text)
+	}
+	if want := time.Date(2025, 10, 16, 0, 0, 0, 0, time.UTC); !card.PublishedAt.Equal(want) {
+		t.Errorf("PublishedAt = %v, want %v", card.PublishedAt, want)
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	if _, err := Parse([]byte(`<html><body></body></html>`)); err == nil {
+		t.Error("expected error for non-feed document")
+	}
+}
@@ -0,0 +1,142 @@
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"oshcity-news-parser/internal/fsutil"
+)
+
+const (
+	ArchiveModeOff    = "off"
+	ArchiveModeRecord = "record"
+	ArchiveModeReplay = "replay"
+)
+
+// ErrNotArchived — в режиме replay ответа для запроса нет в архиве
+var ErrNotArchived = errors.New("response not found in archive")
+
+// Archive — WARC-подобный архив ответов Fetcher: для каждого запроса хранится
+// файл метаданных (.json) и тело (.body), плюс общий журнал index.jsonl
+type Archive struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// archiveRecord — метаданные записанного ответа
+type archiveRecord struct {
+	Type           string      `json:"type"` // всегда "response", по аналогии с WARC-Type
+	TargetURI      string      `json:"target_uri"`
+	ResponseURL    string      `json:"response_url"`
+	AcceptLanguage string      `json:"accept_language,omitempty"`
+	StatusCode     int         `json:"status_code"`
+	Headers        http.Header `json:"headers"`
+	FromCache      bool        `json:"from_cache,omitempty"`
+	RecordedAt     time.Time   `json:"recorded_at"`
+	BodyFile       string      `json:"body_file"`
+	BodySize       int         `json:"body_size"`
+}
+
+func NewArchive(dir string) *Archive {
+	return &Archive{dir: dir}
+}
+
+// Record сохраняет ответ в архив
+func (a *Archive) Record(requestURL, acceptLanguage string, resp *FetchResponse) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	key := archiveKey(requestURL, acceptLanguage)
+	record := archiveRecord{
+		Type:           "response",
+		TargetURI:      requestURL,
+		ResponseURL:    resp.URL,
+		AcceptLanguage: acceptLanguage,
+		StatusCode:     resp.StatusCode,
+		Headers:        resp.Headers,
+		FromCache:      resp.FromCache,
+		RecordedAt:     time.Now().UTC(),
+		BodyFile:       key + ".body",
+		BodySize:       len(resp.Body),
+	}
+
+	metaData, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive record: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(filepath.Join(a.dir, record.BodyFile), resp.Body); err != nil {
+		return err
+	}
+	if err := fsutil.WriteFileAtomic(filepath.Join(a.dir, key+".json"), metaData); err != nil {
+		return err
+	}
+
+	// Журнал всех записей в порядке получения
+	indexLine, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index line: %w", err)
+	}
+
+	indexFile, err := os.OpenFile(filepath.Join(a.dir, "index.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open archive index: %w", err)
+	}
+	defer func() { _ = indexFile.Close() }()
+
+	if _, err := indexFile.Write(append(indexLine, '\n')); err != nil {
+		return fmt.Errorf("failed to write archive index: %w", err)
+	}
+
+	return nil
+}
+
+// Replay возвращает записанный ответ для запроса
+func (a *Archive) Replay(requestURL, acceptLanguage string) (*FetchResponse, error) {
+	key := archiveKey(requestURL, acceptLanguage)
+
+	metaData, err := os.ReadFile(filepath.Join(a.dir, key+".json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotArchived, requestURL)
+		}
+		return nil, fmt.Errorf("failed to read archive record: %w", err)
+	}
+
+	var record archiveRecord
+	if err := json.Unmarshal(metaData, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse archive record: %w", err)
+	}
+
+	body, err := os.ReadFile(filepath.Join(a.dir, record.BodyFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archived body: %w", err)
+	}
+
+	// FromCache не восстанавливаем: в архиве полное тело, и при воспроизведении
+	// страница должна обрабатываться так же, как при первом получении
+	return &FetchResponse{
+		StatusCode: record.StatusCode,
+		Body:       body,
+		URL:        record.ResponseURL,
+		Headers:    record.Headers,
+	}, nil
+}
+
+// archiveKey — ключ записи: SHA256 от URL и Accept-Language
+func archiveKey(requestURL, acceptLanguage string) string {
+	sum := sha256.Sum256([]byte(requestURL + "\n" + acceptLanguage))
+	return hex.EncodeToString(sum[:])
+}
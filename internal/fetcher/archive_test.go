@@ -0,0 +1,121 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/observability"
+)
+
+func TestArchiveRecordReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte("<html>" + r.Header.Get("Accept-Language") + "</html>"))
+	}))
+
+	cfg := &config.Config{
+		HTTP: config.HttpConfig{
+			UserAgent:        "test-agent",
+			ConnectTimeoutMS: 5000,
+		},
+	}
+	logger := observability.NewLogger("", "error", 0, 0, 0)
+	dir := t.TempDir()
+	pageURL := server.URL + "/ru/novosti/"
+
+	recorder := &Fetcher{
+		client:      server.Client(),
+		cfg:         cfg,
+		logger:      logger,
+		robotsCache: NewRobotsCache(defaultRobotsCacheTTL, cfg.HTTP.UserAgent, logger),
+		rateLimiter: NewRateLimiter(1, 1000, 0),
+		archive:     NewArchive(dir),
+		archiveMode: ArchiveModeRecord,
+	}
+
+	recorded, err := recorder.Fetch(context.Background(), pageURL, "ru-RU")
+	if err != nil {
+		t.Fatalf("record fetch error: %v", err)
+	}
+
+	// Сервер больше недоступен — ответы только из архива
+	server.Close()
+
+	replayer := &Fetcher{
+		cfg:         cfg,
+		logger:      logger,
+		archive:     NewArchive(dir),
+		archiveMode: ArchiveModeReplay,
+	}
+
+	replayed, err := replayer.Fetch(context.Background(), pageURL, "ru-RU")
+	if err != nil {
+		t.Fatalf("replay fetch error: %v", err)
+	}
+
+	if replayed.StatusCode != recorded.StatusCode {
+		t.Errorf("StatusCode = %d, want %d", replayed.StatusCode, recorded.StatusCode)
+	}
+	if string(replayed.Body) != "<html>ru-RU</html>" {
+		t.Errorf("Body = %q", string(replayed.Body))
+	}
+	if replayed.Headers.Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type = %q", replayed.Headers.Get("Content-Type"))
+	}
+
+	// Другой Accept-Language — другая запись
+	if _, err := replayer.Fetch(context.Background(), pageURL, "ky-KG"); !errors.Is(err, ErrNotArchived) {
+		t.Errorf("expected ErrNotArchived, got %v", err)
+	}
+}
+
+func TestArchiveReplayRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /private/\n\nSitemap: https://oshcity.gov.kg/sitemap_index.xml\n"))
+	}))
+
+	logger := observability.NewLogger("", "error", 0, 0, 0)
+	dir := t.TempDir()
+
+	recorder := NewRobotsCache(defaultRobotsCacheTTL, "test-agent", logger)
+	recorder.archive = NewArchive(dir)
+	recorder.archiveMode = ArchiveModeRecord
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	if _, err := recorder.Sitemaps(context.Background(), "http", host, server.Client()); err != nil {
+		t.Fatalf("record robots.txt error: %v", err)
+	}
+
+	// Сервер больше недоступен — robots.txt только из архива
+	server.Close()
+
+	replayer := NewRobotsCache(defaultRobotsCacheTTL, "test-agent", logger)
+	replayer.archive = NewArchive(dir)
+	replayer.archiveMode = ArchiveModeReplay
+
+	sitemaps, err := replayer.Sitemaps(context.Background(), "http", host, server.Client())
+	if err != nil {
+		t.Fatalf("replay robots.txt error: %v", err)
+	}
+	if len(sitemaps) != 1 || sitemaps[0] != "https://oshcity.gov.kg/sitemap_index.xml" {
+		t.Errorf("Sitemaps = %v", sitemaps)
+	}
+	if allowed, err := replayer.IsAllowed(context.Background(), host, server.URL+"/private/page", server.Client()); err != nil || allowed {
+		t.Errorf("IsAllowed = %v, %v; want false from archived rules", allowed, err)
+	}
+
+	// robots.txt другого хоста не записан — в replay считается отсутствующим, сеть не нужна
+	sitemaps, err = replayer.Sitemaps(context.Background(), "http", "unreachable.invalid", server.Client())
+	if err != nil || len(sitemaps) != 0 {
+		t.Errorf("Sitemaps for unarchived host = %v, %v; want none", sitemaps, err)
+	}
+}
@@ -0,0 +1,128 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Backend загрузки HTML-страниц (rod.backend)
+const (
+	BackendHTTP    = "http"    // Только HTTP-клиент
+	BackendBrowser = "browser" // Всегда Rod (HTTP — если браузер не запустился)
+	BackendAuto    = "auto"    // HTTP, с переходом на Rod для хоста, если без JS страница пустая
+)
+
+// challengeMarkers — фрагменты страниц-проверок, которые проходятся только с JS
+var challengeMarkers = [][]byte{
+	[]byte("cf-browser-verification"),
+	[]byte("cf_chl_"),
+	[]byte("<title>just a moment...</title>"),
+	[]byte("<title>ddos-guard</title>"),
+	[]byte("enable javascript and cookies to continue"),
+}
+
+// fetchAuto загружает страницу по HTTP и переключает хост на Rod, если ответ похож
+// на JS-проверку или в листинге нет ни одной карточки readySelector.
+// Решение запоминается по хосту: после него страницы хоста больше не проверяются
+func (f *Fetcher) fetchAuto(ctx context.Context, urlStr string, lang string, readySelector string) (*FetchResponse, error) {
+	host := urlHost(urlStr)
+
+	decided := f.hostBackend(host)
+	if decided == BackendBrowser && f.browserAvailable() {
+		return f.fetchRendered(ctx, urlStr, lang, readySelector)
+	}
+
+	resp, err := f.fetchWithHTTPCache(ctx, urlStr, lang, acceptHTML)
+	if err != nil {
+		return nil, err
+	}
+	if decided != "" {
+		return resp, nil
+	}
+
+	reason := browserReason(resp, readySelector)
+	if reason == "" {
+		// Без readySelector (детальная страница) по ответу нельзя судить, хватает ли HTTP
+		if readySelector != "" && isSuccess(resp.StatusCode) {
+			f.setHostBackend(host, BackendHTTP)
+			f.logger.Info("Host served over HTTP", "host", host)
+		}
+		return resp, nil
+	}
+
+	if !f.browserAvailable() {
+		f.logger.Warn("Page needs a browser but Rod is unavailable", "url", urlStr, "reason", reason)
+		return resp, nil
+	}
+
+	f.setHostBackend(host, BackendBrowser)
+	f.logger.Info("Switching host to browser backend", "host", host, "url", urlStr, "reason", reason)
+
+	return f.fetchRendered(ctx, urlStr, lang, readySelector)
+}
+
+// browserReason возвращает причину перехода на браузер ("" — HTTP-ответа достаточно)
+func browserReason(resp *FetchResponse, readySelector string) string {
+	if looksLikeChallenge(resp) {
+		return "js_challenge"
+	}
+
+	// Ошибки (404 за последней страницей и т.п.) не повод запускать браузер
+	if readySelector == "" || !isSuccess(resp.StatusCode) {
+		return ""
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(resp.Body))
+	if err != nil || doc.Find(readySelector).Length() == 0 {
+		return "no_cards"
+	}
+
+	return ""
+}
+
+// looksLikeChallenge распознаёт страницы анти-бот проверок (Cloudflare, DDoS-Guard)
+func looksLikeChallenge(resp *FetchResponse) bool {
+	if resp.Headers.Get("Cf-Mitigated") == "challenge" {
+		return true
+	}
+
+	body := bytes.ToLower(resp.Body)
+	for _, marker := range challengeMarkers {
+		if bytes.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+}
+
+func urlHost(urlStr string) string {
+	parsed, err := url.Parse(urlStr)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+func (f *Fetcher) hostBackend(host string) string {
+	f.hostMu.RLock()
+	defer f.hostMu.RUnlock()
+	return f.hostBackends[host]
+}
+
+func (f *Fetcher) setHostBackend(host string, backend string) {
+	f.hostMu.Lock()
+	defer f.hostMu.Unlock()
+
+	if f.hostBackends == nil {
+		f.hostBackends = make(map[string]string)
+	}
+	f.hostBackends[host] = backend
+}
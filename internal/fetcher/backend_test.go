@@ -0,0 +1,88 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/observability"
+)
+
+func TestBrowserReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		headers  http.Header
+		body     string
+		selector string
+		want     string
+	}{
+		{name: "cards present", status: 200, body: `<article class="post">1</article>`, selector: "article.post", want: ""},
+		{name: "no cards", status: 200, body: `<div id="app"></div>`, selector: "article.post", want: "no_cards"},
+		{name: "no selector", status: 200, body: `<div id="app"></div>`, want: ""},
+		{name: "not found", status: 404, body: `<p>404</p>`, selector: "article.post", want: ""},
+		{name: "cloudflare title", status: 403, body: `<html><head><title>Just a moment...</title></head></html>`, want: "js_challenge"},
+		{name: "cloudflare header", status: 403, headers: http.Header{"Cf-Mitigated": {"challenge"}}, want: "js_challenge"},
+	}
+
+	for _, tt := range tests {
+		headers := tt.headers
+		if headers == nil {
+			headers = make(http.Header)
+		}
+		resp := &FetchResponse{StatusCode: tt.status, Body: []byte(tt.body), Headers: headers}
+		if got := browserReason(resp, tt.selector); got != tt.want {
+			t.Errorf("%s: browserReason = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFetchAutoRemembersHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/challenge" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("<title>Just a moment...</title>"))
+			return
+		}
+		_, _ = w.Write([]byte(`<article class="post">1</article>`))
+	}))
+	defer server.Close()
+
+	newFetcher := func() *Fetcher {
+		f := &Fetcher{
+			client:  server.Client(),
+			cfg:     &config.Config{HTTP: config.HttpConfig{ConnectTimeoutMS: 5000}},
+			logger:  observability.NewLogger("", "error", 0, 0, 0),
+			backend: BackendAuto,
+		}
+		// Браузер считается недоступным без попытки запуска
+		f.rodOnce.Do(func() {})
+		return f
+	}
+
+	ctx := context.Background()
+	host := urlHost(server.URL)
+
+	f := newFetcher()
+	if _, err := f.fetchAuto(ctx, server.URL+"/list", "ru", "article.post"); err != nil {
+		t.Fatalf("fetchAuto error: %v", err)
+	}
+	if got := f.hostBackend(host); got != BackendHTTP {
+		t.Errorf("hostBackend after listing with cards = %q, want %q", got, BackendHTTP)
+	}
+
+	// Без браузера страница проверки отдаётся как есть, решение не запоминается
+	f = newFetcher()
+	resp, err := f.fetchAuto(ctx, server.URL+"/challenge", "ru", "article.post")
+	if err != nil {
+		t.Fatalf("fetchAuto error: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("StatusCode = %d, want 503", resp.StatusCode)
+	}
+	if got := f.hostBackend(host); got != "" {
+		t.Errorf("hostBackend without browser = %q, want empty", got)
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -26,81 +29,358 @@ type Fetcher struct {
 	logger      *observability.Logger
 	robotsCache *RobotsCache
 	rateLimiter *RateLimiter
-	browser     *rod.Browser
-	useRod      bool
+	httpCache   *HTTPCache
+	archive     *Archive
+	archiveMode string
+	backend     string // BackendHTTP | BackendBrowser | BackendAuto
+	proxies     *ProxyPool
+
+	// Браузер запускается один раз: сразу для browser, при первой эскалации для auto.
+	// Если прокси браузера выведен из ротации, браузер перезапускается на следующем (под rodMu)
+	rodOnce      sync.Once
+	rodReady     atomic.Bool
+	rodMu        sync.RWMutex
+	browser      *rod.Browser
+	pages        *pagePool
+	browserProxy *url.URL // Прокси, с которым запущен браузер (nil — без прокси)
+
+	// Решение auto-режима по хосту (BackendHTTP | BackendBrowser)
+	hostMu       sync.RWMutex
+	hostBackends map[string]string
 }
 
+const defaultRobotsCacheTTL = 12 * time.Hour
+
+const (
+	acceptHTML  = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+	acceptImage = "image/avif,image/webp,image/png,image/jpeg,image/*;q=0.8,*/*;q=0.5"
+	acceptJSON  = "application/json"
+	acceptFeed  = "application/rss+xml,application/atom+xml,application/xml;q=0.9,text/xml;q=0.8"
+	acceptXML   = "application/xml,text/xml;q=0.9,*/*;q=0.8"
+)
+
 type FetchResponse struct {
 	StatusCode int
 	Body       []byte
 	URL        string
 	Headers    http.Header
+	FromCache  bool // true — сервер ответил 304, тело взято из HTTP-кэша
 }
 
-func NewFetcher(cfg *config.Config, logger *observability.Logger) *Fetcher {
+// NewFetcher создаёт Fetcher; непригодный ca_bundle — ошибка, а не откат к системным корням
+func NewFetcher(cfg *config.Config, logger *observability.Logger) (*Fetcher, error) {
+	transport := &http.Transport{
+		MaxIdleConns:        cfg.HTTP.MaxIdleConnections,
+		MaxIdleConnsPerHost: cfg.HTTP.MaxIdleConnectionsPerHost,
+		IdleConnTimeout:     cfg.GetIdleConnectionTimeout(),
+	}
+
+	if cfg.HTTP.CABundle != "" {
+		tlsConfig, err := loadCABundle(cfg.HTTP.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
 	client := &http.Client{
-		Timeout: cfg.GetTotalTimeout(),
-		Transport: &http.Transport{
-			MaxIdleConns:        cfg.HTTP.MaxIdleConnections,
-			MaxIdleConnsPerHost: cfg.HTTP.MaxIdleConnectionsPerHost,
-			IdleConnTimeout:     cfg.GetIdleConnectionTimeout(),
-		},
+		Timeout:   cfg.GetTotalTimeout(),
+		Transport: transport,
+	}
+
+	// Прокси выбирается на каждый запрос; браузер — один прокси на запуск (см. relaunchBrowser)
+	var proxies *ProxyPool
+	if len(cfg.Proxy.URLs) > 0 {
+		pool, err := NewProxyPool(cfg.Proxy.URLs, cfg.GetProxyFailureThreshold(), cfg.GetProxyCooldown(), logger)
+		if err != nil {
+			logger.Error("Failed to configure proxies, connecting directly", "error", err.Error())
+		} else {
+			proxies = pool
+			transport.Proxy = proxyFromContext
+			client.Transport = &proxyTransport{base: transport, pool: pool}
+			logger.Info("Outbound proxies enabled", "count", len(cfg.Proxy.URLs))
+		}
+	}
+
+	robotsTTL := cfg.GetRobotsCacheTTL()
+	if robotsTTL <= 0 {
+		robotsTTL = defaultRobotsCacheTTL
 	}
 
 	fetcher := &Fetcher{
 		client:      client,
 		cfg:         cfg,
 		logger:      logger,
-		robotsCache: NewRobotsCache(12 * time.Hour),
-		rateLimiter: NewRateLimiter(cfg.RateLimit.MaxConcurrentPerHost, cfg.RateLimit.RPM),
-		useRod:      true,
+		robotsCache: NewRobotsCache(robotsTTL, cfg.HTTP.UserAgent, logger),
+		rateLimiter: NewRateLimiter(cfg.RateLimit.MaxConcurrentPerHost, cfg.RateLimit.RPM, cfg.RateLimit.Burst),
+		backend:     cfg.GetFetchBackend(),
+		proxies:     proxies,
+	}
+
+	if cfg.HTTPCache.Enabled {
+		fetcher.httpCache = NewHTTPCache(cfg.HTTPCache.Dir)
 	}
 
-	// Инициализируем Rod браузер
-	if fetcher.useRod {
-		fetcher.initRod()
+	fetcher.archiveMode = cfg.Archive.Mode
+	if fetcher.archiveMode == "" {
+		fetcher.archiveMode = ArchiveModeOff
+	}
+	if fetcher.archiveMode != ArchiveModeOff {
+		fetcher.archive = NewArchive(cfg.Archive.Dir)
+		fetcher.robotsCache.archive = fetcher.archive
+		fetcher.robotsCache.archiveMode = fetcher.archiveMode
+		logger.Info("Fetcher archive enabled", "mode", fetcher.archiveMode, "dir", cfg.Archive.Dir)
+	}
+
+	// В режиме replay сеть не используется — браузер не нужен
+	if fetcher.archiveMode == ArchiveModeReplay {
+		fetcher.backend = BackendHTTP
+	}
+
+	logger.Info("Fetcher backend selected", "backend", fetcher.backend)
+
+	// В режиме browser запускаем Rod сразу; в auto — только когда HTTP не справится
+	if fetcher.backend == BackendBrowser {
+		fetcher.browserAvailable()
 	}
 
-	return fetcher
+	return fetcher, nil
 }
 
-func (f *Fetcher) initRod() {
+// browserAvailable запускает браузер при первом вызове; false — Rod недоступен, используется HTTP
+func (f *Fetcher) browserAvailable() bool {
+	f.rodOnce.Do(func() {
+		if err := f.initRod(); err != nil {
+			f.logger.Error("Failed to initialize Rod, falling back to HTTP", "error", err.Error())
+			return
+		}
+		f.rodReady.Store(true)
+	})
+	return f.rodReady.Load()
+}
+
+func (f *Fetcher) initRod() error {
+	var proxyURL *url.URL
+	if f.proxies != nil {
+		proxyURL = f.proxies.Next(time.Now())
+	}
+
+	browser, pages, err := f.launchBrowser(proxyURL)
+	if err != nil {
+		return err
+	}
+	f.browser, f.pages, f.browserProxy = browser, pages, proxyURL
+	return nil
+}
+
+// launchBrowser запускает Chrome с пулом вкладок; proxyURL — прокси браузера (nil — без прокси)
+func (f *Fetcher) launchBrowser(proxyURL *url.URL) (browser *rod.Browser, pages *pagePool, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			f.logger.Error("Failed to initialize Rod", "error", fmt.Sprintf("%v", r))
-			f.useRod = false
+			err = fmt.Errorf("rod panic: %v", r)
 		}
 	}()
 
 	// Используем установленный Chrome вместо скачивания
-	u, err := launcher.New().
-		Bin(f.cfg.Rod.ChromePath).
-		Launch()
+	l := launcher.New().Bin(f.cfg.Rod.ChromePath)
+
+	// Chrome принимает один прокси на запуск: браузер ходит через один прокси пула
+	var proxyAuth *url.Userinfo
+	if proxyURL != nil {
+		l = l.Proxy(chromeProxyServer(proxyURL))
+		proxyAuth = proxyURL.User
+		if proxyAuth != nil && proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+			// Chrome не поддерживает авторизацию на SOCKS-прокси
+			f.logger.Warn("Browser ignores SOCKS proxy credentials", "proxy", proxyURL.Redacted())
+			proxyAuth = nil
+		}
+		f.logger.Info("Browser proxy enabled", "proxy", proxyURL.Redacted())
+	}
+
+	u, err := l.Launch()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to launch browser: %w", err)
+	}
+
+	browser = rod.New().ControlURL(u)
+	if err := browser.Connect(); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to browser: %w", err)
+	}
+
+	// Вкладок не больше, чем параллельных запросов к хосту, если rod.max_pages не задан
+	maxPages := f.cfg.Rod.MaxPages
+	if maxPages <= 0 {
+		maxPages = f.cfg.RateLimit.MaxConcurrentPerHost
+	}
+	pages = newPagePool(browser, maxPages, proxyAuth)
+
+	f.logger.Info("Rod browser initialized successfully", "max_pages", maxPages)
+	return browser, pages, nil
+}
+
+// reportBrowserProxy сообщает пулу прокси о результате загрузки страницы браузером.
+// Если прокси браузера выведен из ротации, браузер перезапускается на следующем доступном
+func (f *Fetcher) reportBrowserProxy(ctx context.Context, proxyURL *url.URL, resp *FetchResponse, err error) {
+	// Отмена запроса вызывающей стороной — не ошибка прокси
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	// 407 — прокси отклонил учётные данные
+	failed := err != nil || resp.StatusCode == http.StatusProxyAuthRequired
+	f.proxies.Report(proxyURL, failed)
+
+	if failed && !f.proxies.Available(proxyURL, time.Now()) {
+		f.relaunchBrowser(proxyURL)
+	}
+}
+
+// relaunchBrowser перезапускает браузер на следующем доступном прокси вместо failed.
+// Ждёт завершения текущих загрузок; если доступного прокси нет или запуск не удался,
+// остаётся прежний браузер
+func (f *Fetcher) relaunchBrowser(failed *url.URL) {
+	f.rodMu.Lock()
+	defer f.rodMu.Unlock()
+
+	// Браузер уже перезапущен другим запросом
+	if f.browserProxy != failed {
+		return
+	}
+
+	now := time.Now()
+	next := f.proxies.Next(now)
+	if next == failed || !f.proxies.Available(next, now) {
+		f.logger.Warn("No healthy proxy to relaunch browser", "proxy", failed.Redacted())
+		return
+	}
 
+	browser, pages, err := f.launchBrowser(next)
 	if err != nil {
-		f.logger.Error("Failed to launch browser", "error", err.Error())
-		f.useRod = false
+		f.logger.Error("Failed to relaunch browser on next proxy", "proxy", next.Redacted(), "error", err.Error())
 		return
 	}
 
-	f.browser = rod.New().ControlURL(u)
-	if err := f.browser.Connect(); err != nil {
-		f.logger.Error("Failed to connect to browser", "error", err.Error())
-		f.useRod = false
+	f.pages.Close()
+	if err := f.browser.Close(); err != nil {
+		f.logger.Warn("Failed to close browser", "error", err.Error())
+	}
+	f.browser, f.pages, f.browserProxy = browser, pages, next
+
+	f.logger.Info("Browser relaunched on next proxy",
+		"failed_proxy", failed.Redacted(),
+		"proxy", next.Redacted(),
+	)
+}
+
+// DropCache удаляет ответ из HTTP-кэша: следующий запрос будет безусловным.
+// Нужен, когда данные страницы не удалось сохранить — иначе ответ 304 скроет её при повторе
+func (f *Fetcher) DropCache(urlStr string) {
+	if f.httpCache == nil {
 		return
 	}
+	if err := f.httpCache.Delete(urlStr); err != nil {
+		f.logger.Warn("Failed to drop HTTP cache entry", "url", urlStr, "error", err.Error())
+	}
+}
 
-	f.logger.Info("Rod browser initialized successfully")
+// Sitemaps возвращает Sitemap-ссылки из robots.txt хоста (в режиме replay — из архива)
+func (f *Fetcher) Sitemaps(ctx context.Context, scheme, host string) ([]string, error) {
+	return f.robotsCache.Sitemaps(ctx, scheme, host, f.client)
 }
 
-func (f *Fetcher) Close() error {
-	if f.browser != nil {
-		return f.browser.Close()
+// Ping проверяет соединение с браузером Rod; в HTTP-режиме всегда nil
+func (f *Fetcher) Ping(ctx context.Context) error {
+	if !f.rodReady.Load() {
+		return nil
+	}
+	f.rodMu.RLock()
+	defer f.rodMu.RUnlock()
+	if _, err := (proto.BrowserGetVersion{}).Call(f.browser.Context(ctx)); err != nil {
+		return fmt.Errorf("browser is not connected: %w", err)
 	}
 	return nil
 }
 
+func (f *Fetcher) Close() error {
+	if !f.rodReady.Load() {
+		return nil
+	}
+	f.rodMu.Lock()
+	defer f.rodMu.Unlock()
+	f.pages.Close()
+	return f.browser.Close()
+}
+
 func (f *Fetcher) Fetch(ctx context.Context, urlStr string, acceptLanguage string) (*FetchResponse, error) {
+	return f.FetchPage(ctx, urlStr, acceptLanguage, "")
+}
+
+// FetchPage загружает HTML-страницу как Fetch; в Rod-режиме ожидает появления readySelector
+// (пустой — только загрузки документа). В HTTP-режиме readySelector не используется
+func (f *Fetcher) FetchPage(ctx context.Context, urlStr string, acceptLanguage string, readySelector string) (*FetchResponse, error) {
+	return f.fetchArchived(ctx, urlStr, acceptLanguage, func(ctx context.Context) (*FetchResponse, error) {
+		return f.fetchOnce(ctx, urlStr, acceptLanguage, readySelector)
+	})
+}
+
+// FetchImage загружает бинарный ресурс (изображение) всегда через HTTP,
+// с проверкой robots.txt, rate limiting и повторами как у Fetch
+func (f *Fetcher) FetchImage(ctx context.Context, urlStr string) (*FetchResponse, error) {
+	return f.fetchArchived(ctx, urlStr, "", func(ctx context.Context) (*FetchResponse, error) {
+		return f.fetchWithHTTP(ctx, urlStr, "", acceptImage, nil)
+	})
+}
+
+// FetchJSON загружает JSON (REST API) всегда через HTTP,
+// с проверкой robots.txt, rate limiting и повторами как у Fetch
+func (f *Fetcher) FetchJSON(ctx context.Context, urlStr string, acceptLanguage string) (*FetchResponse, error) {
+	return f.fetchArchived(ctx, urlStr, acceptLanguage, func(ctx context.Context) (*FetchResponse, error) {
+		return f.fetchWithHTTP(ctx, urlStr, acceptLanguage, acceptJSON, nil)
+	})
+}
+
+// FetchFeed загружает RSS/Atom фид всегда через HTTP (с условным GET, если включён кэш)
+func (f *Fetcher) FetchFeed(ctx context.Context, urlStr string, acceptLanguage string) (*FetchResponse, error) {
+	return f.fetchArchived(ctx, urlStr, acceptLanguage, func(ctx context.Context) (*FetchResponse, error) {
+		return f.fetchWithHTTPCache(ctx, urlStr, acceptLanguage, acceptFeed)
+	})
+}
+
+// FetchSitemap загружает sitemap.xml всегда через HTTP (с условным GET, если включён кэш).
+// При ответе 304 тело берётся из кэша, поэтому FromCache не требует отдельной обработки
+func (f *Fetcher) FetchSitemap(ctx context.Context, urlStr string) (*FetchResponse, error) {
+	return f.fetchArchived(ctx, urlStr, "", func(ctx context.Context) (*FetchResponse, error) {
+		return f.fetchWithHTTPCache(ctx, urlStr, "", acceptXML)
+	})
+}
+
+// fetchArchived в режиме replay отдаёт ответ только из архива,
+// в режиме record — записывает каждый полученный ответ
+func (f *Fetcher) fetchArchived(ctx context.Context, urlStr string, acceptLanguage string, fetchFn func(ctx context.Context) (*FetchResponse, error)) (*FetchResponse, error) {
+	if f.archiveMode == ArchiveModeReplay {
+		resp, err := f.archive.Replay(urlStr, acceptLanguage)
+		if err != nil {
+			return nil, err
+		}
+		f.logger.Debug("Replayed from archive", "url", urlStr, "status", resp.StatusCode, "size", len(resp.Body))
+		return resp, nil
+	}
+
+	resp, err := f.fetchWithRetries(ctx, urlStr, fetchFn)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.archiveMode == ArchiveModeRecord {
+		if err := f.archive.Record(urlStr, acceptLanguage, resp); err != nil {
+			f.logger.Warn("Failed to record response", "url", urlStr, "error", err.Error())
+		}
+	}
+
+	return resp, nil
+}
+
+func (f *Fetcher) fetchWithRetries(ctx context.Context, urlStr string, fetchFn func(ctx context.Context) (*FetchResponse, error)) (*FetchResponse, error) {
 	// Parse URL to get host
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
@@ -118,10 +398,8 @@ func (f *Fetcher) Fetch(ctx context.Context, urlStr string, acceptLanguage strin
 		return nil, fmt.Errorf("URL disallowed by robots.txt: %s", urlStr)
 	}
 
-	// Apply rate limiting
-	if err := f.rateLimiter.Wait(ctx, host); err != nil {
-		return nil, fmt.Errorf("rate limit error: %w", err)
-	}
+	// Crawl-delay из robots.txt
+	f.rateLimiter.SetCrawlDelay(host, f.robotsCache.CrawlDelay(host))
 
 	// Fetch with retries
 	var lastErr error
@@ -135,16 +413,32 @@ func (f *Fetcher) Fetch(ctx context.Context, urlStr string, acceptLanguage strin
 			}
 		}
 
-		resp, err := f.fetchOnce(ctx, urlStr, acceptLanguage)
+		// Apply rate limiting (на каждую попытку: повтор тоже запрос к хосту)
+		release, err := f.rateLimiter.Wait(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit error: %w", err)
+		}
+
+		// Слот хоста занят до конца запроса (max_concurrent_per_host — запросы в полёте)
+		resp, err := fetchFn(ctx)
+		release()
 		if err != nil {
 			lastErr = err
+			if attempt < f.cfg.HTTP.MaxRetries {
+				observability.FetchRetries.WithLabelValues("error").Inc()
+			}
 			continue
 		}
 
+		// 429/503 замедляют хост; пауза Retry-After выдерживается в rateLimiter.Wait перед повтором
+		retryAfter, _ := ParseRetryAfter(resp.Headers.Get("Retry-After"), time.Now())
+		f.rateLimiter.Observe(host, resp.StatusCode, retryAfter)
+
 		// Retry on 5xx or 429
 		if resp.StatusCode >= 500 || resp.StatusCode == 429 {
 			lastErr = fmt.Errorf("server error: %d", resp.StatusCode)
 			if attempt < f.cfg.HTTP.MaxRetries {
+				observability.FetchRetries.WithLabelValues("status").Inc()
 				continue
 			}
 		}
@@ -155,65 +449,224 @@ func (f *Fetcher) Fetch(ctx context.Context, urlStr string, acceptLanguage strin
 	return nil, fmt.Errorf("fetch failed after %d retries: %w", f.cfg.HTTP.MaxRetries, lastErr)
 }
 
-func (f *Fetcher) fetchOnce(ctx context.Context, urlStr string, lang string) (*FetchResponse, error) {
-	// Если Rod доступен и инициализирован, используем его
-	if f.useRod && f.browser != nil {
-		return f.fetchWithRod(ctx, urlStr, lang)
+func (f *Fetcher) fetchOnce(ctx context.Context, urlStr string, lang string, readySelector string) (*FetchResponse, error) {
+	switch f.backend {
+	case BackendBrowser:
+		// Если Rod доступен и инициализирован, используем его
+		if f.browserAvailable() {
+			return f.fetchRendered(ctx, urlStr, lang, readySelector)
+		}
+	case BackendAuto:
+		return f.fetchAuto(ctx, urlStr, lang, readySelector)
 	}
 
-	// Иначе используем обычный HTTP
-	return f.fetchWithHTTP(ctx, urlStr, lang)
+	// Иначе используем обычный HTTP (с условным GET, если включён кэш)
+	return f.fetchWithHTTPCache(ctx, urlStr, lang, acceptHTML)
 }
 
-func (f *Fetcher) fetchWithRod(ctx context.Context, urlStr string, lang string) (*FetchResponse, error) {
+// fetchRendered загружает страницу через Rod с учётом метрик и состояния прокси браузера.
+// Перезапуск браузера (relaunchBrowser) ждёт, пока загрузки держат rodMu на чтение
+func (f *Fetcher) fetchRendered(ctx context.Context, urlStr string, lang string, readySelector string) (*FetchResponse, error) {
+	start := time.Now()
+
+	f.rodMu.RLock()
+	proxyURL := f.browserProxy
+	resp, err := f.fetchWithRod(ctx, f.pages, urlStr, lang, readySelector)
+	f.rodMu.RUnlock()
+
+	observeFetch("rod", start, resp, err)
+	if proxyURL != nil {
+		f.reportBrowserProxy(ctx, proxyURL, resp, err)
+	}
+	return resp, err
+}
+
+// fetchWithHTTPCache выполняет условный GET по ETag/Last-Modified из кэша.
+// На 304 возвращает закэшированное тело с FromCache = true.
+func (f *Fetcher) fetchWithHTTPCache(ctx context.Context, urlStr string, lang string, accept string) (*FetchResponse, error) {
+	if f.httpCache == nil {
+		return f.fetchWithHTTP(ctx, urlStr, lang, accept, nil)
+	}
+
+	entry, cachedBody, cached := f.httpCache.Get(urlStr)
+
+	var extraHeaders http.Header
+	if cached {
+		extraHeaders = entry.validatorHeaders()
+	}
+
+	resp, err := f.fetchWithHTTP(ctx, urlStr, lang, accept, extraHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		f.logger.Info("Not modified, using cached body", "url", urlStr, "size", len(cachedBody))
+		return &FetchResponse{
+			StatusCode: entry.StatusCode,
+			Body:       cachedBody,
+			URL:        urlStr,
+			Headers:    entry.Headers,
+			FromCache:  true,
+		}, nil
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		if err := f.httpCache.Put(urlStr, resp); err != nil {
+			f.logger.Warn("Failed to store HTTP cache entry", "url", urlStr, "error", err.Error())
+		}
+	}
+
+	return resp, nil
+}
+
+// fetchWithRod загружает страницу во вкладке из пула. Запрос ограничен ctx и rod.page_timeout_s;
+// статус и заголовки берутся из ответа на основной документ.
+// readySelector (например, card_selectors листинга) — признак готовности страницы вместо фиксированной паузы
+func (f *Fetcher) fetchWithRod(ctx context.Context, pages *pagePool, urlStr string, lang string, readySelector string) (*FetchResponse, error) {
 	f.logger.Debug("Fetching with Rod", "url", urlStr)
 
-	page := f.browser.MustPage(urlStr)
+	ctx, cancel := context.WithTimeout(ctx, f.cfg.GetRodPageTimeout())
+	defer cancel()
 
-	// Устанавливаем headers как в HTTP клиенте
-	page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
-		UserAgent: f.cfg.HTTP.UserAgent,
-	})
+	page, err := pages.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire browser page: %w", err)
+	}
 
-	// Эмулируем браузер
-	page.MustEval(`() => {
-		Object.defineProperty(navigator, 'webdriver', {
-			get: () => false,
-		})
-	}`)
+	// Статус и заголовки основного документа — из событий сети
+	document := newDocumentResponse(page.FrameID)
+	eventsCtx, stopEvents := context.WithCancel(ctx)
+	waitResponse := page.Context(eventsCtx).EachEvent(document.observe)
+	eventsDone := make(chan struct{})
+	go func() {
+		defer close(eventsDone)
+		waitResponse()
+	}()
 
+	healthy := false
 	defer func() {
-		_ = page.Close()
+		// Подписка на события должна завершиться до того, как вкладку возьмёт другой запрос
+		stopEvents()
+		<-eventsDone
+		pages.Put(page, healthy)
 	}()
 
-	// Ждём загрузки с timeout
-	if err := page.Timeout(time.Duration(f.cfg.Rod.WaitLoadTimeoutS) * time.Second).WaitLoad(); err != nil {
+	p := page.Context(ctx)
+	waitTimeout := time.Duration(f.cfg.Rod.WaitLoadTimeoutS) * time.Second
+
+	// Устанавливаем headers как в HTTP клиенте
+	err = p.SetUserAgent(&proto.NetworkSetUserAgentOverride{
+		UserAgent:      f.cfg.HTTP.UserAgent,
+		AcceptLanguage: lang,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set user agent: %w", err)
+	}
+
+	if err := p.Navigate(urlStr); err != nil {
+		return nil, fmt.Errorf("failed to navigate: %w", err)
+	}
+
+	if err := p.Timeout(waitTimeout).WaitLoad(); err != nil {
 		return nil, fmt.Errorf("failed to wait for page load: %w", err)
 	}
 
-	// Ждём всех сетевых запросов (idle state)
-	if err := page.WaitIdle(time.Duration(f.cfg.Rod.WaitLoadTimeoutS) * time.Second); err != nil {
-		f.logger.Warn("Wait idle timeout", "url", urlStr, "error", err.Error())
-		// Не возвращаем ошибку — продолжаем с уже загруженным контентом
+	status, headers := document.result()
+
+	err = f.waitReady(ctx, urlStr, status, readySelector, func() error {
+		_, err := p.Timeout(waitTimeout).Element(readySelector)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Даём lazy-load догрузиться, но не дольше rod.lazy_load_delay_s
+	if lazyLoad := time.Duration(f.cfg.Rod.LazyLoadDelayS) * time.Second; lazyLoad > 0 {
+		if err := p.WaitIdle(lazyLoad); err != nil {
+			f.logger.Debug("Wait idle timeout", "url", urlStr, "error", err.Error())
+		}
 	}
 
-	// Задержка для lazy-load
-	time.Sleep(time.Duration(f.cfg.Rod.LazyLoadDelayS) * time.Second)
+	html, err := p.HTML()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page HTML: %w", err)
+	}
 
-	// Получаем полный HTML
-	html := page.MustHTML()
+	finalURL := urlStr
+	if info, err := p.Info(); err == nil && info.URL != "" {
+		finalURL = info.URL
+	}
 
-	f.logger.Info("Fetched with Rod successfully", "size", len(html))
+	healthy = true
+	f.logger.Info("Fetched with Rod successfully", "status", status, "size", len(html))
 
 	return &FetchResponse{
-		StatusCode: 200,
+		StatusCode: status,
 		Body:       []byte(html),
-		URL:        urlStr,
-		Headers:    make(http.Header),
+		URL:        finalURL,
+		Headers:    headers,
 	}, nil
 }
 
-func (f *Fetcher) fetchWithHTTP(ctx context.Context, urlStr string, acceptLanguage string) (*FetchResponse, error) {
+// waitReady ждёт появления контента (readySelector) через wait. На ошибочных ответах
+// контента не будет — не ждём. Таймаут селектора не ошибка: страница сохраняется как есть,
+// ошибка — только отмена ctx
+func (f *Fetcher) waitReady(ctx context.Context, urlStr string, status int, readySelector string, wait func() error) error {
+	if readySelector == "" || status >= http.StatusBadRequest {
+		return nil
+	}
+
+	if err := wait(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to wait for page content: %w", ctx.Err())
+		}
+		f.logger.Warn("Ready selector not found", "url", urlStr, "selector", readySelector, "error", err.Error())
+	}
+	return nil
+}
+
+// documentResponse собирает статус и заголовки основного документа вкладки из событий сети
+type documentResponse struct {
+	frameID proto.PageFrameID
+
+	mu         sync.Mutex
+	statusCode int
+	headers    http.Header
+}
+
+func newDocumentResponse(frameID proto.PageFrameID) *documentResponse {
+	return &documentResponse{frameID: frameID, headers: make(http.Header)}
+}
+
+// observe учитывает событие ответа; true — получен ответ документа, подписку можно завершить
+func (d *documentResponse) observe(e *proto.NetworkResponseReceived) bool {
+	if e.Type != proto.NetworkResourceTypeDocument || e.FrameID != d.frameID {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statusCode = e.Response.Status
+	for key, value := range e.Response.Headers {
+		d.headers.Set(key, value.Str())
+	}
+	return true
+}
+
+// result возвращает статус и заголовки документа
+func (d *documentResponse) result() (int, http.Header) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.statusCode == 0 {
+		// Событие ответа не получено (например, документ из кэша браузера) — считаем успешным
+		return http.StatusOK, d.headers
+	}
+	return d.statusCode, d.headers
+}
+
+func (f *Fetcher) fetchWithHTTP(ctx context.Context, urlStr string, acceptLanguage string, accept string, extraHeaders http.Header) (*FetchResponse, error) {
 	f.logger.Info("Fetching with HTTP", "url", urlStr)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
@@ -222,17 +675,26 @@ func (f *Fetcher) fetchWithHTTP(ctx context.Context, urlStr string, acceptLangua
 	}
 
 	req.Header.Set("User-Agent", f.cfg.HTTP.UserAgent)
-	req.Header.Set("Accept-Language", acceptLanguage)
+	if acceptLanguage != "" {
+		req.Header.Set("Accept-Language", acceptLanguage)
+	}
 	req.Header.Set("Accept-Encoding", "gzip, deflate")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept", accept)
 	req.Header.Set("Connection", "keep-alive")
+	for key, values := range extraHeaders {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
 
 	// Set connect timeout separately
 	ctx, cancel := context.WithTimeout(ctx, f.cfg.GetConnectTimeout())
 	defer cancel()
 
+	start := time.Now()
 	resp, err := f.client.Do(req.WithContext(ctx))
 	if err != nil {
+		observeFetch("http", start, nil, err)
 		return nil, err
 	}
 	defer func() {
@@ -253,17 +715,32 @@ func (f *Fetcher) fetchWithHTTP(ctx context.Context, urlStr string, acceptLangua
 
 	body, err := io.ReadAll(reader)
 	if err != nil {
+		observeFetch("http", start, nil, err)
 		return nil, err
 	}
 
 	f.logger.Info("Fetched with HTTP successfully", "size", len(body))
 
-	return &FetchResponse{
+	fetchResp := &FetchResponse{
 		StatusCode: resp.StatusCode,
 		Body:       body,
 		URL:        resp.Request.URL.String(),
 		Headers:    resp.Header,
-	}, nil
+	}
+	observeFetch("http", start, fetchResp, nil)
+
+	return fetchResp, nil
+}
+
+// observeFetch учитывает попытку запроса в метриках pages_fetched / fetch_duration
+func observeFetch(backend string, start time.Time, resp *FetchResponse, err error) {
+	observability.FetchDuration.WithLabelValues(backend).Observe(time.Since(start).Seconds())
+
+	code := "error"
+	if err == nil && resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	observability.PagesFetched.WithLabelValues(backend, code).Inc()
 }
 
 func (f *Fetcher) calculateBackoff(attempt int) time.Duration {
@@ -2,22 +2,28 @@ package fetcher
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/observability"
 )
 
 func TestBackoffCalculation(t *testing.T) {
 	cfg := &config.Config{
-		HTTP: config.HttpConfig{
-			BackoffMinMS: 250,
-			BackoffMaxMS: 2000,
-			JitterPct:    20,
+		Backoff: config.BackoffConfig{
+			MinMS:     250,
+			MaxMS:     2000,
+			JitterPct: 20,
 		},
 	}
 
-	fetcher := NewFetcher(cfg)
+	// NewFetcher запускает браузер — для расчёта backoff достаточно конфига
+	fetcher := &Fetcher{cfg: cfg}
 
 	for attempt := 1; attempt <= 5; attempt++ {
 		backoff := fetcher.calculateBackoff(attempt)
@@ -28,15 +34,16 @@ func TestBackoffCalculation(t *testing.T) {
 }
 
 func TestRateLimiter(t *testing.T) {
-	rl := NewRateLimiter(2, 10)
+	rl := NewRateLimiter(2, 10, 0)
 	ctx := context.Background()
 
 	start := time.Now()
 	for i := 0; i < 5; i++ {
-		err := rl.Wait(ctx, "example.com")
+		release, err := rl.Wait(ctx, "example.com")
 		if err != nil {
 			t.Fatalf("Rate limiter error: %v", err)
 		}
+		release()
 	}
 	elapsed := time.Since(start)
 
@@ -44,3 +51,166 @@ func TestRateLimiter(t *testing.T) {
 		t.Logf("Rate limiter OK: 5 requests in %v", elapsed)
 	}
 }
+
+func TestRateLimiterCrawlDelay(t *testing.T) {
+	rl := NewRateLimiter(2, 1000, 0)
+	rl.SetCrawlDelay("example.com", 50*time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := rl.Wait(ctx, "example.com")
+		if err != nil {
+			t.Fatalf("Rate limiter error: %v", err)
+		}
+		release()
+	}
+	elapsed := time.Since(start)
+
+	// 3 запроса с Crawl-delay 50ms: минимум 2 интервала
+	if elapsed < 100*time.Millisecond {
+		t.Errorf("Crawl-delay not applied: 3 requests in %v", elapsed)
+	}
+}
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	// 600 rpm = 10 запросов в секунду, без запаса: интервал 100ms
+	rl := NewRateLimiter(1, 600, 1)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := rl.Wait(ctx, "example.com")
+		if err != nil {
+			t.Fatalf("Rate limiter error: %v", err)
+		}
+		release()
+	}
+	elapsed := time.Since(start)
+
+	if elapsed < 180*time.Millisecond {
+		t.Errorf("Token bucket not applied: 3 requests in %v", elapsed)
+	}
+}
+
+func TestRateLimiterAdaptive(t *testing.T) {
+	rl := NewRateLimiter(1, 100, 0)
+
+	tests := []struct {
+		name   string
+		status int
+		want   float64
+	}{
+		{name: "throttled", status: 429, want: 50},
+		{name: "throttled again", status: 503, want: 25},
+		{name: "client error keeps rate", status: 404, want: 25},
+		{name: "success recovers", status: 200, want: 30},
+	}
+
+	for _, tt := range tests {
+		rl.Observe("example.com", tt.status, 0)
+		if got := rl.Rate("example.com"); got != tt.want {
+			t.Errorf("%s: Rate = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Скорость не опускается ниже 10% rpm
+	for i := 0; i < 10; i++ {
+		rl.Observe("example.com", 429, 0)
+	}
+	if got := rl.Rate("example.com"); got != 10 {
+		t.Errorf("Rate after repeated 429 = %v, want 10", got)
+	}
+}
+
+func TestRateLimiterRetryAfter(t *testing.T) {
+	rl := NewRateLimiter(1, 1000, 0)
+	rl.Observe("example.com", 429, 150*time.Millisecond)
+
+	start := time.Now()
+	release, err := rl.Wait(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Rate limiter error: %v", err)
+	}
+	release()
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("Retry-After not applied: waited %v", elapsed)
+	}
+}
+
+func TestFetchConcurrencyPerHost(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		HTTP:      config.HttpConfig{ConnectTimeoutMS: 5000},
+		RateLimit: config.RateLimitConfig{MaxConcurrentPerHost: 1, RPM: 60000},
+	}
+	logger := observability.NewLogger("", "error", 0, 0, 0)
+	f := &Fetcher{
+		client:      server.Client(),
+		cfg:         cfg,
+		logger:      logger,
+		robotsCache: NewRobotsCache(defaultRobotsCacheTTL, cfg.HTTP.UserAgent, logger),
+		rateLimiter: NewRateLimiter(cfg.RateLimit.MaxConcurrentPerHost, cfg.RateLimit.RPM, 0),
+	}
+
+	// max_concurrent_per_host ограничивает запросы в полёте, а не только ожидание токена
+	const fetches = 5
+	var wg sync.WaitGroup
+	for i := 0; i < fetches; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.FetchJSON(context.Background(), server.URL+"/wp-json/wp/v2/posts", ""); err != nil {
+				t.Errorf("FetchJSON error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := maxInFlight.Load(); got != 1 {
+		t.Errorf("max requests in flight = %d, want 1", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "120", want: 2 * time.Minute, wantOK: true},
+		{value: " 0 ", want: 0, wantOK: true},
+		{value: "Sat, 18 Oct 2025 12:00:30 GMT", want: 30 * time.Second, wantOK: true},
+		{value: "Sat, 18 Oct 2025 11:00:00 GMT", want: 0, wantOK: true},
+		{value: "", wantOK: false},
+		{value: "-5", wantOK: false},
+		{value: "soon", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseRetryAfter(tt.value, now)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"oshcity-news-parser/internal/fsutil"
+)
+
+// HTTPCache — дисковый кэш ответов для условных GET (ETag / Last-Modified)
+type HTTPCache struct {
+	dir string
+}
+
+// httpCacheEntry — метаданные закэшированного ответа (тело хранится в отдельном файле)
+type httpCacheEntry struct {
+	URL          string      `json:"url"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	StatusCode   int         `json:"status_code"`
+	Headers      http.Header `json:"headers"`
+	StoredAt     time.Time   `json:"stored_at"`
+}
+
+func NewHTTPCache(dir string) *HTTPCache {
+	return &HTTPCache{dir: dir}
+}
+
+// Get возвращает метаданные и тело закэшированного ответа
+func (c *HTTPCache) Get(urlStr string) (*httpCacheEntry, []byte, bool) {
+	metaPath, bodyPath := c.paths(urlStr)
+
+	metaData, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	var entry httpCacheEntry
+	if err := json.Unmarshal(metaData, &entry); err != nil {
+		return nil, nil, false
+	}
+
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	return &entry, body, true
+}
+
+// Put сохраняет ответ, если у него есть валидаторы (ETag или Last-Modified)
+func (c *HTTPCache) Put(urlStr string, resp *FetchResponse) error {
+	etag := resp.Headers.Get("ETag")
+	lastModified := resp.Headers.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return nil
+	}
+
+	entry := httpCacheEntry{
+		URL:          urlStr,
+		ETag:         etag,
+		LastModified: lastModified,
+		StatusCode:   resp.StatusCode,
+		Headers:      resp.Headers,
+		StoredAt:     time.Now().UTC(),
+	}
+
+	metaData, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	metaPath, bodyPath := c.paths(urlStr)
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Сначала тело, затем метаданные — запись метаданных «фиксирует» элемент кэша
+	if err := fsutil.WriteFileAtomic(bodyPath, resp.Body); err != nil {
+		return err
+	}
+	if err := fsutil.WriteFileAtomic(metaPath, metaData); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete удаляет элемент кэша; отсутствующий элемент не ошибка
+func (c *HTTPCache) Delete(urlStr string) error {
+	metaPath, bodyPath := c.paths(urlStr)
+
+	// Сначала метаданные — без них элемент уже не используется
+	for _, path := range []string{metaPath, bodyPath} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove cache file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validatorHeaders возвращает заголовки условного запроса If-None-Match / If-Modified-Since
+func (e *httpCacheEntry) validatorHeaders() http.Header {
+	headers := make(http.Header)
+	if e.ETag != "" {
+		headers.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		headers.Set("If-Modified-Since", e.LastModified)
+	}
+	return headers
+}
+
+func (c *HTTPCache) paths(urlStr string) (metaPath, bodyPath string) {
+	sum := sha256.Sum256([]byte(urlStr))
+	key := hex.EncodeToString(sum[:])
+	base := filepath.Join(c.dir, key[:2], key)
+	return base + ".json", base + ".body"
+}
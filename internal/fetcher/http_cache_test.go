@@ -0,0 +1,66 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/observability"
+)
+
+func TestFetchWithHTTPCacheNotModified(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("<html>listing</html>"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		HTTP: config.HttpConfig{
+			UserAgent:        "test-agent",
+			ConnectTimeoutMS: 5000,
+		},
+	}
+
+	f := &Fetcher{
+		client:    server.Client(),
+		cfg:       cfg,
+		logger:    observability.NewLogger("", "error", 0, 0, 0),
+		httpCache: NewHTTPCache(t.TempDir()),
+	}
+
+	ctx := context.Background()
+
+	first, err := f.fetchWithHTTPCache(ctx, server.URL, "ru-RU", acceptHTML)
+	if err != nil {
+		t.Fatalf("first fetch error: %v", err)
+	}
+	if first.FromCache {
+		t.Errorf("first fetch should not come from cache")
+	}
+
+	second, err := f.fetchWithHTTPCache(ctx, server.URL, "ru-RU", acceptHTML)
+	if err != nil {
+		t.Fatalf("second fetch error: %v", err)
+	}
+	if !second.FromCache {
+		t.Errorf("second fetch should come from cache")
+	}
+	if second.StatusCode != http.StatusOK {
+		t.Errorf("cached StatusCode = %d, want 200", second.StatusCode)
+	}
+	if string(second.Body) != "<html>listing</html>" {
+		t.Errorf("cached Body = %q", string(second.Body))
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
@@ -0,0 +1,181 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"oshcity-news-parser/internal/observability"
+)
+
+// ProxyPool — ротация исходящих прокси (round-robin) с учётом их состояния:
+// после failureThreshold ошибок подряд прокси выводится из ротации на cooldown
+type ProxyPool struct {
+	proxies          []*proxyState
+	failureThreshold int
+	cooldown         time.Duration
+	logger           *observability.Logger
+
+	mu   sync.Mutex
+	next int
+}
+
+type proxyState struct {
+	url       *url.URL
+	failures  int       // Ошибок подряд
+	downUntil time.Time // До этого момента прокси вне ротации
+}
+
+func NewProxyPool(rawURLs []string, failureThreshold int, cooldown time.Duration, logger *observability.Logger) (*ProxyPool, error) {
+	pool := &ProxyPool{
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		logger:           logger,
+	}
+
+	for i, rawURL := range rawURLs {
+		proxyURL, err := url.Parse(rawURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL at index %d", i)
+		}
+		pool.proxies = append(pool.proxies, &proxyState{url: proxyURL})
+		observability.ProxyUp.WithLabelValues(proxyURL.Redacted()).Set(1)
+	}
+
+	return pool, nil
+}
+
+// Next возвращает следующий прокси в ротации. Если все выведены из ротации,
+// берётся тот, что вернётся раньше всех: запрос в обход прокси недопустим
+func (p *ProxyPool) Next(now time.Time) *url.URL {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var fallback *proxyState
+	for range p.proxies {
+		state := p.proxies[p.next]
+		p.next = (p.next + 1) % len(p.proxies)
+
+		if !now.Before(state.downUntil) {
+			return state.url
+		}
+		if fallback == nil || state.downUntil.Before(fallback.downUntil) {
+			fallback = state
+		}
+	}
+
+	return fallback.url
+}
+
+// Available — прокси в ротации (не выведен на cooldown)
+func (p *ProxyPool) Available(proxyURL *url.URL, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, state := range p.proxies {
+		if state.url == proxyURL {
+			return !now.Before(state.downUntil)
+		}
+	}
+	return false
+}
+
+// Report учитывает результат запроса через прокси
+func (p *ProxyPool) Report(proxyURL *url.URL, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, state := range p.proxies {
+		if state.url != proxyURL {
+			continue
+		}
+
+		if !failed {
+			if state.failures >= p.failureThreshold {
+				p.logger.Info("Proxy recovered", "proxy", proxyURL.Redacted())
+				observability.ProxyUp.WithLabelValues(proxyURL.Redacted()).Set(1)
+			}
+			state.failures = 0
+			return
+		}
+
+		state.failures++
+		if state.failures >= p.failureThreshold {
+			state.downUntil = time.Now().Add(p.cooldown)
+			p.logger.Warn("Proxy removed from rotation",
+				"proxy", proxyURL.Redacted(),
+				"failures", state.failures,
+				"cooldown", p.cooldown.String(),
+			)
+			observability.ProxyUp.WithLabelValues(proxyURL.Redacted()).Set(0)
+		}
+		return
+	}
+}
+
+// proxyContextKey — прокси, выбранный proxyTransport для запроса
+type proxyContextKey struct{}
+
+// proxyTransport выбирает прокси на каждый запрос и сообщает пулу о результате.
+// Сам прокси подставляет base.Proxy (см. proxyFromContext)
+type proxyTransport struct {
+	base *http.Transport
+	pool *ProxyPool
+}
+
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxyURL := t.pool.Next(time.Now())
+	req = req.WithContext(context.WithValue(req.Context(), proxyContextKey{}, proxyURL))
+
+	resp, err := t.base.RoundTrip(req)
+
+	// Отмена запроса вызывающей стороной — не ошибка прокси
+	if err != nil && req.Context().Err() != nil {
+		return resp, err
+	}
+
+	// 407 — прокси отклонил учётные данные
+	failed := err != nil || resp.StatusCode == http.StatusProxyAuthRequired
+	t.pool.Report(proxyURL, failed)
+
+	return resp, err
+}
+
+// proxyFromContext — http.Transport.Proxy для proxyTransport
+func proxyFromContext(req *http.Request) (*url.URL, error) {
+	proxyURL, _ := req.Context().Value(proxyContextKey{}).(*url.URL)
+	return proxyURL, nil
+}
+
+// loadCABundle добавляет сертификаты из PEM-файла к системным корневым
+func loadCABundle(path string) (*tls.Config, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+
+	return &tls.Config{RootCAs: roots}, nil
+}
+
+// chromeProxyServer — значение --proxy-server для Chrome (без учётных данных: Chrome их из флага не принимает)
+func chromeProxyServer(proxyURL *url.URL) string {
+	scheme := proxyURL.Scheme
+	if scheme == "socks5h" {
+		scheme = "socks5"
+	}
+	return scheme + "://" + proxyURL.Host
+}
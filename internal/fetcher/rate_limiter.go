@@ -2,82 +2,231 @@ package fetcher
 
 import (
 	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"oshcity-news-parser/internal/observability"
+)
+
+const (
+	// maxRetryAfter — верхняя граница паузы по Retry-After (защита от заведомо больших значений)
+	maxRetryAfter = 10 * time.Minute
+
+	// minRateFraction — ниже этой доли rate_limit.rpm скорость не снижается
+	minRateFraction = 0.1
+
+	// recoverRateFraction — на сколько (доля rpm) скорость растёт после каждого успешного ответа
+	recoverRateFraction = 0.05
 )
 
+// RateLimiter — token bucket по хосту с адаптивной скоростью:
+// на 429/503 скорость снижается вдвое (и выдерживается Retry-After), на успешных ответах постепенно восстанавливается
 type RateLimiter struct {
 	maxConcurrent  int
 	rpm            int
+	burst          int
 	hostSemaphores map[string]*hostLimiter
 	mu             sync.RWMutex
 }
 
 type hostLimiter struct {
-	sem      chan struct{} // Semaphore for concurrency
-	lastTime time.Time
-	requests int
-	mu       sync.Mutex
+	sem         chan struct{} // Semaphore for concurrency
+	rate        float64       // Текущая скорость, запросов в минуту
+	tokens      float64
+	lastRefill  time.Time
+	pausedUntil time.Time     // Retry-After: до этого момента запросы к хосту не начинаются
+	crawlDelay  time.Duration // Crawl-delay из robots.txt
+	nextAllowed time.Time     // Время, раньше которого нельзя начинать следующий запрос (Crawl-delay)
+	mu          sync.Mutex
 }
 
-func NewRateLimiter(maxConcurrent, rpm int) *RateLimiter {
+// NewRateLimiter создаёт limiter; burst — ёмкость корзины (0 — rpm, как у окна в минуту)
+func NewRateLimiter(maxConcurrent, rpm, burst int) *RateLimiter {
+	if burst <= 0 {
+		burst = rpm
+	}
 	return &RateLimiter{
 		maxConcurrent:  maxConcurrent,
 		rpm:            rpm,
+		burst:          burst,
 		hostSemaphores: make(map[string]*hostLimiter),
 	}
 }
 
-func (rl *RateLimiter) Wait(ctx context.Context, host string) error {
+// SetCrawlDelay задаёт минимальный интервал между запросами к хосту (Crawl-delay из robots.txt)
+func (rl *RateLimiter) SetCrawlDelay(host string, delay time.Duration) {
+	limiter := rl.getHostLimiter(host)
+
+	limiter.mu.Lock()
+	limiter.crawlDelay = delay
+	limiter.mu.Unlock()
+}
+
+func (rl *RateLimiter) getHostLimiter(host string) *hostLimiter {
 	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
 	limiter, exists := rl.hostSemaphores[host]
 	if !exists {
 		limiter = &hostLimiter{
-			sem: make(chan struct{}, rl.maxConcurrent),
+			sem:        make(chan struct{}, rl.maxConcurrent),
+			rate:       float64(rl.rpm),
+			tokens:     float64(rl.burst),
+			lastRefill: time.Now(),
 		}
 		rl.hostSemaphores[host] = limiter
+		observability.HostRateLimit.WithLabelValues(host).Set(limiter.rate)
 	}
-	rl.mu.Unlock()
+	return limiter
+}
+
+// Wait занимает слот хоста (rate_limit.max_concurrent_per_host) и ждёт токен и Crawl-delay.
+// release освобождает слот: вызывается, когда запрос завершён, чтобы лимит ограничивал
+// запросы в полёте, а не только ожидание токена. При ошибке слот уже освобождён
+func (rl *RateLimiter) Wait(ctx context.Context, host string) (release func(), err error) {
+	limiter := rl.getHostLimiter(host)
 
 	// Acquire semaphore (concurrency control)
 	select {
 	case limiter.sem <- struct{}{}:
 	case <-ctx.Done():
-		return ctx.Err()
+		return nil, ctx.Err()
+	}
+
+	release = sync.OnceFunc(func() { <-limiter.sem })
+
+	for {
+		waitTime := rl.take(limiter, time.Now())
+		if waitTime <= 0 {
+			break
+		}
+
+		select {
+		case <-time.After(waitTime):
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
 	}
 
-	defer func() { <-limiter.sem }()
+	if err := rl.waitCrawlDelay(ctx, limiter); err != nil {
+		release()
+		return nil, err
+	}
 
-	// Apply RPM throttle
+	return release, nil
+}
+
+// take забирает токен; если токена нет или хост на паузе — возвращает, сколько ждать до следующей попытки
+func (rl *RateLimiter) take(limiter *hostLimiter, now time.Time) time.Duration {
 	limiter.mu.Lock()
-	now := time.Now()
+	defer limiter.mu.Unlock()
 
-	// Reset counters if minute has passed
-	if now.Sub(limiter.lastTime) > time.Minute {
-		limiter.requests = 0
-		limiter.lastTime = now
+	if now.Before(limiter.pausedUntil) {
+		return limiter.pausedUntil.Sub(now)
 	}
 
-	// Check if we've exceeded RPM
-	if limiter.requests >= rl.rpm {
-		waitTime := time.Minute - now.Sub(limiter.lastTime)
-		limiter.mu.Unlock()
+	// Ёмкость корзины уменьшается вместе со скоростью, чтобы замедление действовало сразу
+	capacity := math.Max(1, float64(rl.burst)*limiter.rate/float64(rl.rpm))
+	perSecond := limiter.rate / 60
 
-		select {
-		case <-time.After(waitTime):
-			limiter.mu.Lock()
-			limiter.requests = 0
-			limiter.lastTime = time.Now()
-			limiter.requests++
-			limiter.mu.Unlock()
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
+	limiter.tokens = math.Min(capacity, limiter.tokens+now.Sub(limiter.lastRefill).Seconds()*perSecond)
+	limiter.lastRefill = now
+
+	if limiter.tokens >= 1 {
+		limiter.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - limiter.tokens) / perSecond * float64(time.Second))
+}
+
+// Observe учитывает ответ хоста: 429/503 снижают скорость вдвое и ставят паузу Retry-After,
+// успешные ответы постепенно возвращают скорость к rate_limit.rpm
+func (rl *RateLimiter) Observe(host string, statusCode int, retryAfter time.Duration) {
+	limiter := rl.getHostLimiter(host)
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	maxRate := float64(rl.rpm)
+	switch {
+	case statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable:
+		limiter.rate = math.Max(maxRate*minRateFraction, limiter.rate/2)
+		limiter.tokens = 0
+		if retryAfter > 0 {
+			limiter.pausedUntil = time.Now().Add(min(retryAfter, maxRetryAfter))
 		}
+	case statusCode < http.StatusBadRequest:
+		limiter.rate = math.Min(maxRate, limiter.rate+maxRate*recoverRateFraction)
+	default:
+		return
 	}
 
-	limiter.requests++
+	observability.HostRateLimit.WithLabelValues(host).Set(limiter.rate)
+}
+
+// Rate возвращает текущую скорость для хоста, запросов в минуту
+func (rl *RateLimiter) Rate(host string) float64 {
+	limiter := rl.getHostLimiter(host)
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	return limiter.rate
+}
+
+// waitCrawlDelay резервирует слот с учётом Crawl-delay и ждёт его наступления
+func (rl *RateLimiter) waitCrawlDelay(ctx context.Context, limiter *hostLimiter) error {
+	limiter.mu.Lock()
+	if limiter.crawlDelay <= 0 {
+		limiter.mu.Unlock()
+		return nil
+	}
+
+	now := time.Now()
+	slot := limiter.nextAllowed
+	if slot.Before(now) {
+		slot = now
+	}
+	limiter.nextAllowed = slot.Add(limiter.crawlDelay)
 	limiter.mu.Unlock()
 
-	return nil
+	waitTime := slot.Sub(now)
+	if waitTime <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(waitTime):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ParseRetryAfter разбирает заголовок Retry-After: число секунд или HTTP-дата.
+// false — заголовка нет или он некорректен
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		// Дата в прошлом — повторять можно сразу
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
 }
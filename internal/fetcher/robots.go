@@ -1,90 +1,469 @@
 package fetcher
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"oshcity-news-parser/internal/observability"
 )
 
+// maxRobotsSize — ограничение размера robots.txt (как у Google — 500 KiB)
+const maxRobotsSize = 500 * 1024
+
+// robotsServerErrorTTL — сколько держать запрет всего хоста после 5xx на robots.txt (не дольше ttl кэша)
+const robotsServerErrorTTL = 5 * time.Minute
+
 type RobotsCache struct {
-	cache  map[string]*RobotsTxt
-	ttl    time.Duration
-	mu     sync.RWMutex
-	logger *observability.Logger
+	cache     map[string]*RobotsTxt
+	ttl       time.Duration
+	userAgent string
+	mu        sync.RWMutex
+	logger    *observability.Logger
+
+	// archive — архив ответов Fetcher: в режиме record robots.txt записывается,
+	// в режиме replay читается только из архива (без сети)
+	archive     *Archive
+	archiveMode string
 }
 
 type RobotsTxt struct {
 	content   string
+	rules     *RobotsRules
 	expiresAt time.Time
 }
 
-func NewRobotsCache(ttl time.Duration) *RobotsCache {
+// RobotsRules — разобранный robots.txt
+type RobotsRules struct {
+	groups   []*robotsGroup
+	Sitemaps []string
+}
+
+// robotsGroup — группа правил для набора User-agent
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+func NewRobotsCache(ttl time.Duration, userAgent string, logger *observability.Logger) *RobotsCache {
 	return &RobotsCache{
-		cache: make(map[string]*RobotsTxt),
-		ttl:   ttl,
+		cache:     make(map[string]*RobotsTxt),
+		ttl:       ttl,
+		userAgent: userAgent,
+		logger:    logger,
 	}
 }
 
 func (rc *RobotsCache) IsAllowed(ctx context.Context, host, urlStr string, client *http.Client) (bool, error) {
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return false, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	rules, err := rc.get(ctx, parsedURL.Scheme, host, client)
+	if err != nil {
+		// Не удалось получить robots.txt: считаем, что разрешено
+		return true, nil
+	}
+
+	return rules.IsAllowed(rc.userAgent, robotsPath(parsedURL)), nil
+}
+
+// CrawlDelay возвращает Crawl-delay для хоста из закэшированного robots.txt (0 — не задан)
+func (rc *RobotsCache) CrawlDelay(host string) time.Duration {
+	rc.mu.RLock()
+	cached, exists := rc.cache[host]
+	rc.mu.RUnlock()
+
+	if !exists {
+		return 0
+	}
+	return cached.rules.CrawlDelay(rc.userAgent)
+}
+
+// Sitemaps возвращает Sitemap-ссылки из robots.txt хоста (загружая его при необходимости)
+func (rc *RobotsCache) Sitemaps(ctx context.Context, scheme, host string, client *http.Client) ([]string, error) {
+	rules, err := rc.get(ctx, scheme, host, client)
+	if err != nil {
+		return nil, err
+	}
+	return rules.Sitemaps, nil
+}
+
+// get возвращает правила хоста из кэша или загружает robots.txt
+func (rc *RobotsCache) get(ctx context.Context, scheme, host string, client *http.Client) (*RobotsRules, error) {
 	rc.mu.RLock()
 	cached, exists := rc.cache[host]
 	rc.mu.RUnlock()
 
 	if exists && time.Now().Before(cached.expiresAt) {
 		// Cache hit
-		return !isDisallowedByRobots(cached.content, urlStr), nil
+		return cached.rules, nil
+	}
+
+	if scheme == "" {
+		scheme = "https"
 	}
 
 	// Fetch robots.txt
-	robotsURL := fmt.Sprintf("https://%s/robots.txt", host)
+	robotsURL := fmt.Sprintf("%s://%s/robots.txt", scheme, host)
+	resp, err := rc.fetch(ctx, robotsURL, client)
+	if err != nil {
+		// Network error: не кэшируем, попробуем в следующий раз
+		return nil, err
+	}
+
+	content := ""
+	ttl := rc.ttl
+	var rules *RobotsRules
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		content = string(resp.Body)
+		rules = ParseRobots(content)
+	case resp.StatusCode >= 500:
+		// Сервер недоступен: по RFC 9309 запрещено всё. Кэшируем ненадолго,
+		// чтобы не запрашивать robots.txt перед каждой страницей хоста
+		if rc.logger != nil {
+			rc.logger.Warn("robots.txt unavailable, disallowing host",
+				"host", host,
+				"status", resp.StatusCode,
+			)
+		}
+		rules = disallowAllRobots()
+		ttl = min(robotsServerErrorTTL, rc.ttl)
+	default:
+		// 4xx — robots.txt нет, разрешено всё (кэшируем пустые правила)
+		rules = ParseRobots("")
+	}
+
+	// Cache it
+	rc.mu.Lock()
+	rc.cache[host] = &RobotsTxt{
+		content:   content,
+		rules:     rules,
+		expiresAt: time.Now().Add(ttl),
+	}
+	rc.mu.Unlock()
+
+	if rc.logger != nil {
+		rc.logger.Debug("robots.txt loaded",
+			"host", host,
+			"status", resp.StatusCode,
+			"groups", len(rules.groups),
+			"sitemaps", len(rules.Sitemaps),
+		)
+	}
+
+	return rules, nil
+}
+
+// disallowAllRobots возвращает правила, запрещающие весь сайт
+func disallowAllRobots() *RobotsRules {
+	return &RobotsRules{groups: []*robotsGroup{{
+		agents: []string{"*"},
+		rules:  []robotsRule{{allow: false, pattern: "/"}},
+	}}}
+}
+
+// fetch загружает robots.txt (тело не больше maxRobotsSize) с учётом режима архива.
+// В режиме replay отсутствие robots.txt в архиве означает, что его нет на сайте
+func (rc *RobotsCache) fetch(ctx context.Context, robotsURL string, client *http.Client) (*FetchResponse, error) {
+	if rc.archiveMode == ArchiveModeReplay {
+		resp, err := rc.archive.Replay(robotsURL, "")
+		if errors.Is(err, ErrNotArchived) {
+			return &FetchResponse{StatusCode: http.StatusNotFound, URL: robotsURL}, nil
+		}
+		return resp, err
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
 	if err != nil {
-		// If we can't fetch, assume allowed
-		return true, nil
+		return nil, fmt.Errorf("failed to create robots.txt request: %w", err)
 	}
+	req.Header.Set("User-Agent", rc.userAgent)
 
-	resp, err := client.Do(req)
+	httpResp, err := client.Do(req)
 	if err != nil {
-		// Network error: assume allowed
-		return true, nil
+		return nil, fmt.Errorf("failed to fetch robots.txt: %w", err)
 	}
 	defer func() {
-		if err := resp.Body.Close(); err != nil {
+		if err := httpResp.Body.Close(); err != nil {
 			log.Printf("Failed to close response body: %v", err)
 		}
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		// No robots.txt: assume allowed
-		return true, nil
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, maxRobotsSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read robots.txt: %w", err)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return true, nil
+	resp := &FetchResponse{
+		StatusCode: httpResp.StatusCode,
+		Body:       body,
+		URL:        robotsURL,
+		Headers:    httpResp.Header,
 	}
 
-	content := string(body)
+	if rc.archiveMode == ArchiveModeRecord {
+		if err := rc.archive.Record(robotsURL, "", resp); err != nil && rc.logger != nil {
+			rc.logger.Warn("Failed to record response", "url", robotsURL, "error", err.Error())
+		}
+	}
 
-	// Cache it
-	rc.mu.Lock()
-	rc.cache[host] = &RobotsTxt{
-		content:   content,
-		expiresAt: time.Now().Add(rc.ttl),
+	return resp, nil
+}
+
+// ParseRobots разбирает содержимое robots.txt
+func ParseRobots(content string) *RobotsRules {
+	rules := &RobotsRules{}
+
+	var current *robotsGroup
+	// lastWasAgent — предыдущая значимая строка была User-agent (несколько подряд — одна группа)
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRobotsSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx > -1 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || !lastWasAgent {
+				current = &robotsGroup{}
+				rules.groups = append(rules.groups, current)
+			}
+			current.agents = append(current.agents, robotsProductToken(value))
+			lastWasAgent = true
+		case "allow", "disallow":
+			lastWasAgent = false
+			if current == nil {
+				continue
+			}
+			// Пустой Disallow означает "разрешено всё" — правило не добавляем
+			if value == "" {
+				continue
+			}
+			// Шаблон кодируется так же, как путь запроса (RFC 9309 §2.2.2)
+			current.rules = append(current.rules, robotsRule{
+				allow:   key == "allow",
+				pattern: encodeRobotsPath(value),
+			})
+		case "crawl-delay":
+			lastWasAgent = false
+			if current == nil {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		case "sitemap":
+			// Sitemap не привязан к группе
+			if value != "" {
+				rules.Sitemaps = append(rules.Sitemaps, value)
+			}
+		default:
+			lastWasAgent = false
+		}
 	}
-	rc.mu.Unlock()
 
-	return !isDisallowedByRobots(content, urlStr), nil
+	return rules
+}
+
+// IsAllowed проверяет путь по правилам группы, подходящей под userAgent.
+// Побеждает самое длинное совпавшее правило; при равной длине — Allow.
+func (r *RobotsRules) IsAllowed(userAgent, path string) bool {
+	group := r.groupFor(userAgent)
+	if group == nil {
+		return true
+	}
+
+	// robots.txt сам по себе всегда разрешён
+	if path == "/robots.txt" {
+		return true
+	}
+
+	matchedLen := -1
+	allowed := true
+	for _, rule := range group.rules {
+		if !matchRobotsPattern(rule.pattern, path) {
+			continue
+		}
+		patternLen := len(rule.pattern)
+		if patternLen > matchedLen || (patternLen == matchedLen && rule.allow) {
+			matchedLen = patternLen
+			allowed = rule.allow
+		}
+	}
+
+	return allowed
+}
+
+// CrawlDelay возвращает Crawl-delay группы, подходящей под userAgent
+func (r *RobotsRules) CrawlDelay(userAgent string) time.Duration {
+	group := r.groupFor(userAgent)
+	if group == nil {
+		return 0
+	}
+	return group.crawlDelay
+}
+
+// groupFor выбирает группу, User-agent которой совпадает с product token нашего userAgent;
+// иначе — группу "*". Несколько групп с одним User-agent объединяются (RFC 9309)
+func (r *RobotsRules) groupFor(userAgent string) *robotsGroup {
+	token := robotsProductToken(userAgent)
+
+	matched := false
+	hasWildcard := false
+
+	for _, group := range r.groups {
+		for _, agent := range group.agents {
+			switch {
+			case agent == "*":
+				hasWildcard = true
+			case agent != "" && agent == token:
+				matched = true
+			}
+		}
+	}
+
+	switch {
+	case matched:
+		return r.mergeGroups(token)
+	case hasWildcard:
+		return r.mergeGroups("*")
+	}
+	return nil
+}
+
+// robotsProductToken возвращает product token User-agent в нижнем регистре:
+// "Googlebot/2.1 (+http://...)" -> "googlebot"
+func robotsProductToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	if idx := strings.IndexAny(token, " \t"); idx > -1 {
+		token = token[:idx]
+	}
+	return strings.ToLower(token)
+}
+
+// mergeGroups объединяет правила всех групп, в которых указан agent.
+// Из Crawl-delay берётся наибольший
+func (r *RobotsRules) mergeGroups(agent string) *robotsGroup {
+	merged := &robotsGroup{agents: []string{agent}}
+	for _, group := range r.groups {
+		for _, groupAgent := range group.agents {
+			if groupAgent != agent {
+				continue
+			}
+			merged.rules = append(merged.rules, group.rules...)
+			if group.crawlDelay > merged.crawlDelay {
+				merged.crawlDelay = group.crawlDelay
+			}
+			break
+		}
+	}
+	return merged
+}
+
+// matchRobotsPattern сопоставляет путь с шаблоном robots.txt (поддерживаются * и $)
+func matchRobotsPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+
+	// Первая часть должна совпасть с началом пути
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+
+	for i := 1; i < len(parts); i++ {
+		part := parts[i]
+		if i == len(parts)-1 && anchored {
+			// Последняя часть при $ должна совпасть с концом пути
+			return strings.HasSuffix(path[pos:], part)
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+
+	if anchored {
+		return pos == len(path)
+	}
+	return true
+}
+
+// robotsPath возвращает путь с query для сопоставления с правилами
+func robotsPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return encodeRobotsPath(path)
+}
+
+// encodeRobotsPath приводит путь или шаблон к одному виду: байты вне US-ASCII и управляющие
+// символы кодируются как %XX, шестнадцатеричные цифры существующих %XX — в верхнем регистре.
+// Так "Disallow: /новости/" совпадает с запросом "/%D0%BD%D0%BE..."
+func encodeRobotsPath(path string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '%' && i+2 < len(path) && isHexDigit(path[i+1]) && isHexDigit(path[i+2]):
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(path[i+1 : i+3]))
+			i += 2
+		case c >= 0x80 || c <= 0x20 || c == 0x7f:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&0x0f])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
 }
 
-func isDisallowedByRobots(robotsContent, urlStr string) bool {
-	// TODO: implement full robots.txt parsing with proper User-agent matching
-	// For now: allow all (production: use github.com/viktorruskai/urllib)
-	return false
+func isHexDigit(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
 }
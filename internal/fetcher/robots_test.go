@@ -0,0 +1,248 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const testRobots = `
+# robots.txt для тестов
+User-agent: *
+Disallow: /wp-admin/
+Allow: /wp-admin/admin-ajax.php
+Disallow: /*?s=
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: Googlebot
+User-agent: Bingbot
+Disallow: /private/
+
+User-agent: BadBot
+Disallow: /
+
+Sitemap: https://oshcity.gov.kg/sitemap_index.xml
+`
+
+func TestRobotsIsAllowed(t *testing.T) {
+	rules := ParseRobots(testRobots)
+	userAgent := "Mozilla/5.0 (iPhone; CPU iPhone OS 14_0 like Mac OS X)"
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"/ru/novosti/", true},
+		{"/wp-admin/options.php", false},
+		{"/wp-admin/admin-ajax.php", true},
+		{"/ru/?s=новости", false},
+		{"/files/report.pdf", false},
+		{"/files/report.pdf?download=1", true},
+		{"/robots.txt", true},
+	}
+
+	for _, tt := range tests {
+		result := rules.IsAllowed(userAgent, tt.path)
+		if result != tt.expected {
+			t.Errorf("IsAllowed(%q) = %v, want %v", tt.path, result, tt.expected)
+		}
+	}
+}
+
+func TestRobotsGroupMatching(t *testing.T) {
+	rules := ParseRobots(testRobots)
+
+	// Группа из нескольких User-agent подряд
+	if rules.IsAllowed("Bingbot/2.0 (compatible)", "/private/page") {
+		t.Errorf("Bingbot should be disallowed from /private/")
+	}
+	// Специфичная группа заменяет "*"
+	if !rules.IsAllowed("Googlebot/2.1", "/wp-admin/options.php") {
+		t.Errorf("Googlebot group should not inherit rules from *")
+	}
+	if rules.IsAllowed("BadBot/1.0", "/ru/novosti/") {
+		t.Errorf("BadBot should be disallowed everywhere")
+	}
+}
+
+func TestRobotsProductTokenMatching(t *testing.T) {
+	rules := ParseRobots(`
+User-agent: bot
+Disallow: /
+
+User-agent: go
+Disallow: /
+`)
+
+	tests := []struct {
+		userAgent string
+		expected  bool
+	}{
+		// Группа выбирается по product token целиком, а не по вхождению подстроки
+		{"BadBot/1.0", true},
+		{"Googlebot-compatible/2.1", true},
+		{"Mozilla/5.0 (compatible; bot)", true},
+		{"Bot/1.0", false},
+		{"Go", false},
+	}
+
+	for _, tt := range tests {
+		if result := rules.IsAllowed(tt.userAgent, "/ru/novosti/"); result != tt.expected {
+			t.Errorf("IsAllowed(%q) = %v, want %v", tt.userAgent, result, tt.expected)
+		}
+	}
+}
+
+func TestRobotsNonASCIIRules(t *testing.T) {
+	rules := ParseRobots(`
+User-agent: *
+Disallow: /новости/
+Allow: /новости/%d0%b0рхив
+`)
+
+	tests := []struct {
+		rawURL   string
+		expected bool
+	}{
+		{"https://oshcity.gov.kg/новости/123", false},
+		{"https://oshcity.gov.kg/%D0%BD%D0%BE%D0%B2%D0%BE%D1%81%D1%82%D0%B8/123", false},
+		{"https://oshcity.gov.kg/%d0%bd%d0%be%d0%b2%d0%be%d1%81%d1%82%d0%b8/123", false},
+		{"https://oshcity.gov.kg/новости/архив/2024", true},
+		{"https://oshcity.gov.kg/ru/новости/", true},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.rawURL)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.rawURL, err)
+		}
+		if result := rules.IsAllowed("oshcity-news-parser", robotsPath(u)); result != tt.expected {
+			t.Errorf("IsAllowed(%q) = %v, want %v", tt.rawURL, result, tt.expected)
+		}
+	}
+}
+
+func TestRobotsCrawlDelayAndSitemaps(t *testing.T) {
+	rules := ParseRobots(testRobots)
+
+	if delay := rules.CrawlDelay("Mozilla/5.0"); delay != 2*time.Second {
+		t.Errorf("CrawlDelay = %v, want 2s", delay)
+	}
+	if delay := rules.CrawlDelay("Googlebot"); delay != 0 {
+		t.Errorf("CrawlDelay for Googlebot = %v, want 0", delay)
+	}
+
+	if len(rules.Sitemaps) != 1 || rules.Sitemaps[0] != "https://oshcity.gov.kg/sitemap_index.xml" {
+		t.Errorf("Sitemaps = %v", rules.Sitemaps)
+	}
+}
+
+func TestRobotsEmpty(t *testing.T) {
+	rules := ParseRobots("")
+	if !rules.IsAllowed("any", "/anything") {
+		t.Errorf("Empty robots.txt should allow everything")
+	}
+}
+
+func TestRobotsMergesSameAgentGroups(t *testing.T) {
+	rules := ParseRobots(`
+User-agent: *
+Disallow: /wp-admin/
+Crawl-delay: 1
+
+User-agent: Googlebot
+Disallow: /private/
+
+User-agent: *
+Disallow: /tmp/
+Crawl-delay: 3
+
+User-agent: googlebot
+Disallow: /drafts/
+`)
+
+	tests := []struct {
+		userAgent string
+		path      string
+		expected  bool
+	}{
+		{"Mozilla/5.0", "/wp-admin/options.php", false},
+		{"Mozilla/5.0", "/tmp/file", false},
+		{"Mozilla/5.0", "/ru/novosti/", true},
+		{"Googlebot/2.1", "/private/page", false},
+		{"Googlebot/2.1", "/drafts/page", false},
+		{"Googlebot/2.1", "/tmp/file", true},
+	}
+
+	for _, tt := range tests {
+		if result := rules.IsAllowed(tt.userAgent, tt.path); result != tt.expected {
+			t.Errorf("IsAllowed(%q, %q) = %v, want %v", tt.userAgent, tt.path, result, tt.expected)
+		}
+	}
+
+	if delay := rules.CrawlDelay("Mozilla/5.0"); delay != 3*time.Second {
+		t.Errorf("CrawlDelay = %v, want 3s from merged groups", delay)
+	}
+}
+
+func TestRobotsCacheStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		expected bool
+	}{
+		{name: "not found allows everything", status: http.StatusNotFound, expected: true},
+		{name: "server error disallows everything", status: http.StatusServiceUnavailable, expected: false},
+	}
+
+	for _, tt := range tests {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(tt.status)
+		}))
+
+		serverURL, err := url.Parse(server.URL)
+		if err != nil {
+			t.Fatalf("%s: parse server URL: %v", tt.name, err)
+		}
+		rc := NewRobotsCache(time.Hour, "oshcity-news-parser", nil)
+
+		// Ответ кэшируется: на несколько страниц хоста robots.txt запрашивается один раз
+		for _, path := range []string{"/ru/novosti/", "/ru/novosti/page/2/"} {
+			allowed, err := rc.IsAllowed(context.Background(), serverURL.Host, server.URL+path, server.Client())
+			if err != nil {
+				t.Errorf("%s: IsAllowed(%s) error: %v", tt.name, path, err)
+			}
+			if allowed != tt.expected {
+				t.Errorf("%s: IsAllowed(%s) = %v, want %v", tt.name, path, allowed, tt.expected)
+			}
+		}
+		server.Close()
+
+		if requests != 1 {
+			t.Errorf("%s: robots.txt requested %d times, want 1", tt.name, requests)
+		}
+	}
+
+	// Запрет после 5xx кэшируется ненадолго и не дольше общего TTL
+	rc := NewRobotsCache(time.Minute, "oshcity-news-parser", nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("parse server URL: %v", err)
+	}
+	if _, err := rc.IsAllowed(context.Background(), serverURL.Host, server.URL+"/", server.Client()); err != nil {
+		t.Fatalf("IsAllowed error: %v", err)
+	}
+	if ttl := time.Until(rc.cache[serverURL.Host].expiresAt); ttl > time.Minute {
+		t.Errorf("disallow cached for %v, want at most the cache TTL", ttl)
+	}
+}
@@ -0,0 +1,200 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// hideWebdriverJS выполняется до скриптов страницы в каждом документе вкладки
+const hideWebdriverJS = `Object.defineProperty(navigator, 'webdriver', {
+	get: () => false,
+})`
+
+// pageBackend открывает и закрывает вкладки пула. Браузер — browserPages;
+// в тестах подменяется, чтобы проверять пул без Chrome
+type pageBackend interface {
+	open() (*rod.Page, error)
+	close(page *rod.Page)
+}
+
+// pagePool — ограниченный пул вкладок браузера. Вкладки переиспользуются между запросами;
+// одновременно открыто не больше size вкладок, ожидание слота прерывается отменой ctx
+type pagePool struct {
+	pages pageBackend
+	slots chan struct{}
+	idle  chan *rod.Page
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func newPagePool(browser *rod.Browser, size int, proxyAuth *url.Userinfo) *pagePool {
+	return newPagePoolWith(&browserPages{
+		browser:   browser,
+		proxyAuth: proxyAuth,
+		cancels:   make(map[*rod.Page]context.CancelFunc),
+	}, size)
+}
+
+func newPagePoolWith(pages pageBackend, size int) *pagePool {
+	if size <= 0 {
+		size = 1
+	}
+	return &pagePool{
+		pages: pages,
+		slots: make(chan struct{}, size),
+		idle:  make(chan *rod.Page, size),
+	}
+}
+
+// Get занимает слот пула и возвращает свободную вкладку (или открывает новую).
+// Вкладку нужно вернуть через Put
+func (p *pagePool) Get(ctx context.Context) (*rod.Page, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case page := <-p.idle:
+		return page, nil
+	default:
+	}
+
+	page, err := p.pages.open()
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	return page, nil
+}
+
+// Put возвращает вкладку в пул и освобождает слот.
+// Вкладка после ошибки закрывается: её состояние (незавершённая навигация, диалоги) неизвестно
+func (p *pagePool) Put(page *rod.Page, healthy bool) {
+	defer func() { <-p.slots }()
+
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+
+	if !healthy || closed {
+		p.pages.close(page)
+		return
+	}
+
+	select {
+	case p.idle <- page:
+	default:
+		p.pages.close(page)
+	}
+}
+
+// Close закрывает свободные вкладки; занятые закрываются при возврате через Put
+func (p *pagePool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	for {
+		select {
+		case page := <-p.idle:
+			p.pages.close(page)
+		default:
+			return
+		}
+	}
+}
+
+// browserPages — вкладки браузера Rod
+type browserPages struct {
+	browser   *rod.Browser
+	proxyAuth *url.Userinfo // Учётные данные прокси браузера (nil — без авторизации)
+
+	mu      sync.Mutex
+	cancels map[*rod.Page]context.CancelFunc // Остановка обработчиков событий вкладки
+}
+
+// open открывает пустую вкладку с настройками, общими для всех запросов
+func (p *browserPages) open() (*rod.Page, error) {
+	page, err := p.browser.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open browser page: %w", err)
+	}
+
+	// Network включён постоянно: подписка на ответы в fetchWithRod не выключает его по завершении,
+	// и вкладку можно сразу отдать следующему запросу
+	if err := (proto.NetworkEnable{}).Call(page); err != nil {
+		_ = page.Close()
+		return nil, fmt.Errorf("failed to enable network events: %w", err)
+	}
+
+	if _, err := page.EvalOnNewDocument(hideWebdriverJS); err != nil {
+		_ = page.Close()
+		return nil, fmt.Errorf("failed to set up browser page: %w", err)
+	}
+
+	if p.proxyAuth != nil {
+		if err := p.handleProxyAuth(page); err != nil {
+			_ = page.Close()
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// handleProxyAuth отвечает на запросы авторизации прокси учётными данными из proxy.urls:
+// Chrome не принимает их в --proxy-server. Остальные перехваченные запросы продолжаются без изменений
+func (p *browserPages) handleProxyAuth(page *rod.Page) error {
+	if err := (proto.FetchEnable{HandleAuthRequests: true}).Call(page); err != nil {
+		return fmt.Errorf("failed to enable proxy authentication: %w", err)
+	}
+
+	password, _ := p.proxyAuth.Password()
+	credentials := &proto.FetchAuthChallengeResponse{
+		Response: proto.FetchAuthChallengeResponseResponseProvideCredentials,
+		Username: p.proxyAuth.Username(),
+		Password: password,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.mu.Lock()
+	p.cancels[page] = cancel
+	p.mu.Unlock()
+
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.FetchRequestPaused) {
+			_ = proto.FetchContinueRequest{RequestID: e.RequestID}.Call(page)
+		},
+		func(e *proto.FetchAuthRequired) {
+			response := &proto.FetchAuthChallengeResponse{Response: proto.FetchAuthChallengeResponseResponseCancelAuth}
+			if e.AuthChallenge.Source == proto.FetchAuthChallengeSourceProxy {
+				response = credentials
+			}
+			_ = proto.FetchContinueWithAuth{RequestID: e.RequestID, AuthChallengeResponse: response}.Call(page)
+		},
+	)
+	go wait()
+
+	return nil
+}
+
+// close закрывает вкладку и останавливает её обработчики событий
+func (p *browserPages) close(page *rod.Page) {
+	p.mu.Lock()
+	cancel, ok := p.cancels[page]
+	delete(p.cancels, page)
+	p.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+	_ = page.Close()
+}
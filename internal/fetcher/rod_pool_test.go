@@ -0,0 +1,264 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+
+	"oshcity-news-parser/internal/observability"
+)
+
+// fakePages — pageBackend без браузера: вкладки — пустые *rod.Page, различимые по указателю
+type fakePages struct {
+	mu      sync.Mutex
+	opened  []*rod.Page
+	closed  []*rod.Page
+	openErr error
+}
+
+func (f *fakePages) open() (*rod.Page, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.openErr != nil {
+		return nil, f.openErr
+	}
+	page := &rod.Page{}
+	f.opened = append(f.opened, page)
+	return page, nil
+}
+
+func (f *fakePages) close(page *rod.Page) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = append(f.closed, page)
+}
+
+func (f *fakePages) counts() (opened, closed int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.opened), len(f.closed)
+}
+
+func TestPagePoolBlocksAtCapacity(t *testing.T) {
+	pages := &fakePages{}
+	pool := newPagePoolWith(pages, 1)
+
+	first, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+
+	// Пул заполнен: ожидание слота прерывается отменой ctx
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get at capacity error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// Ожидающий Get получает вкладку, как только её вернут
+	got := make(chan *rod.Page, 1)
+	go func() {
+		page, err := pool.Get(context.Background())
+		if err != nil {
+			t.Errorf("waiting Get error: %v", err)
+		}
+		got <- page
+	}()
+
+	select {
+	case <-got:
+		t.Fatalf("Get returned while the pool was at capacity")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	pool.Put(first, true)
+	select {
+	case page := <-got:
+		if page != first {
+			t.Errorf("waiting Get returned a new page, want the returned one")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("waiting Get did not return after Put")
+	}
+
+	if opened, _ := pages.counts(); opened != 1 {
+		t.Errorf("opened = %d, want 1", opened)
+	}
+}
+
+func TestPagePoolReuseAndDiscard(t *testing.T) {
+	pages := &fakePages{}
+	pool := newPagePoolWith(pages, 2)
+	ctx := context.Background()
+
+	// Исправная вкладка возвращается в пул и переиспользуется
+	first, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	pool.Put(first, true)
+
+	reused, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if reused != first {
+		t.Errorf("Get opened a new page, want the idle one reused")
+	}
+
+	// Вкладка после ошибки закрывается и больше не выдаётся
+	pool.Put(reused, false)
+	if _, closed := pages.counts(); closed != 1 {
+		t.Errorf("closed = %d, want broken page closed", closed)
+	}
+
+	next, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if next == first {
+		t.Errorf("Get returned the discarded page")
+	}
+	if opened, _ := pages.counts(); opened != 2 {
+		t.Errorf("opened = %d, want 2", opened)
+	}
+}
+
+func TestPagePoolOpenErrorReleasesSlot(t *testing.T) {
+	pages := &fakePages{openErr: errors.New("browser disconnected")}
+	pool := newPagePoolWith(pages, 1)
+
+	if _, err := pool.Get(context.Background()); err == nil {
+		t.Fatalf("expected open error")
+	}
+
+	// Слот освобождён: следующий Get не ждёт
+	pages.mu.Lock()
+	pages.openErr = nil
+	pages.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := pool.Get(ctx); err != nil {
+		t.Errorf("Get after open error: %v", err)
+	}
+}
+
+func TestPagePoolClose(t *testing.T) {
+	pages := &fakePages{}
+	pool := newPagePoolWith(pages, 2)
+	ctx := context.Background()
+
+	idle, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	busy, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	pool.Put(idle, true)
+
+	// Close закрывает свободные вкладки, занятые — при возврате
+	pool.Close()
+	if _, closed := pages.counts(); closed != 1 {
+		t.Errorf("closed after Close = %d, want 1 idle page", closed)
+	}
+	if len(pool.idle) != 0 {
+		t.Errorf("idle pages after Close = %d, want 0", len(pool.idle))
+	}
+
+	pool.Put(busy, true)
+	if _, closed := pages.counts(); closed != 2 {
+		t.Errorf("closed after Put = %d, want busy page closed", closed)
+	}
+	if len(pool.idle) != 0 {
+		t.Errorf("page returned to a closed pool")
+	}
+}
+
+func TestDocumentResponse(t *testing.T) {
+	const mainFrame = proto.PageFrameID("main")
+
+	var notFound proto.NetworkResponse
+	if err := json.Unmarshal([]byte(`{"status":404,"headers":{"Content-Type":"text/html; charset=utf-8"}}`), &notFound); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		event *proto.NetworkResponseReceived
+		done  bool
+	}{
+		{name: "image", event: &proto.NetworkResponseReceived{Type: proto.NetworkResourceTypeImage, FrameID: mainFrame, Response: &proto.NetworkResponse{Status: 500}}},
+		{name: "iframe document", event: &proto.NetworkResponseReceived{Type: proto.NetworkResourceTypeDocument, FrameID: "ad", Response: &proto.NetworkResponse{Status: 500}}},
+		{name: "main document", event: &proto.NetworkResponseReceived{Type: proto.NetworkResourceTypeDocument, FrameID: mainFrame, Response: &notFound}, done: true},
+	}
+
+	document := newDocumentResponse(mainFrame)
+	if status, _ := document.result(); status != http.StatusOK {
+		t.Errorf("status without events = %d, want 200", status)
+	}
+
+	for _, tt := range tests {
+		if done := document.observe(tt.event); done != tt.done {
+			t.Errorf("%s: observe = %v, want %v", tt.name, done, tt.done)
+		}
+	}
+
+	status, headers := document.result()
+	if status != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 from the main document", status)
+	}
+	if got := headers.Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	f := &Fetcher{logger: observability.NewLogger("", "error", 0, 0, 0)}
+	timeout := errors.New("context deadline exceeded")
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		status   int
+		selector string
+		waitErr  error
+		wantWait bool
+		wantErr  bool
+	}{
+		{name: "no selector", ctx: context.Background(), status: http.StatusOK},
+		{name: "error status skips wait", ctx: context.Background(), status: http.StatusNotFound, selector: "article"},
+		{name: "found", ctx: context.Background(), status: http.StatusOK, selector: "article", wantWait: true},
+		{name: "selector timeout keeps page", ctx: context.Background(), status: http.StatusOK, selector: "article", waitErr: timeout, wantWait: true},
+		{name: "cancelled request fails", ctx: cancelled, status: http.StatusOK, selector: "article", waitErr: context.Canceled, wantWait: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		waited := false
+		err := f.waitReady(tt.ctx, "https://oshcity.gov.kg/ru/", tt.status, tt.selector, func() error {
+			waited = true
+			return tt.waitErr
+		})
+		if waited != tt.wantWait {
+			t.Errorf("%s: waited = %v, want %v", tt.name, waited, tt.wantWait)
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.body")
+
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFileAtomic(path, []byte(content)); err != nil {
+			t.Fatalf("WriteFileAtomic(%q) error: %v", content, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read file: %v", err)
+		}
+		if string(data) != content {
+			t.Errorf("file = %q, want %q", data, content)
+		}
+	}
+
+	// Временные файлы не остаются в каталоге
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the target file", len(entries))
+	}
+
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "file"), []byte("x")); err == nil {
+		t.Errorf("expected error for missing directory")
+	}
+}
@@ -0,0 +1,88 @@
+package images
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/fetcher"
+	"oshcity-news-parser/internal/fsutil"
+	"oshcity-news-parser/internal/observability"
+)
+
+// Downloader загружает миниатюры карточек через Fetcher и кэширует их на диске
+type Downloader struct {
+	fetcher  *fetcher.Fetcher
+	cacheDir string
+	maxBytes int
+	logger   *observability.Logger
+}
+
+func NewDownloader(cfg *config.Config, f *fetcher.Fetcher, logger *observability.Logger) *Downloader {
+	return &Downloader{
+		fetcher:  f,
+		cacheDir: cfg.Images.CacheDir,
+		maxBytes: cfg.Images.MaxSizeKB * 1024,
+		logger:   logger,
+	}
+}
+
+// Download возвращает байты изображения: из дискового кэша или загрузив по URL
+func (d *Downloader) Download(ctx context.Context, imageURL string) ([]byte, error) {
+	if imageURL == "" {
+		return nil, fmt.Errorf("image URL is empty")
+	}
+
+	cachePath := d.cachePath(imageURL)
+
+	// Cache hit
+	if data, err := os.ReadFile(cachePath); err == nil {
+		d.logger.Debug("Image loaded from cache", "url", imageURL, "size", len(data))
+		return data, nil
+	}
+
+	resp, err := d.fetcher.FetchImage(ctx, imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected image status: %d", resp.StatusCode)
+	}
+	if len(resp.Body) == 0 {
+		return nil, fmt.Errorf("empty image body")
+	}
+	if len(resp.Body) > d.maxBytes {
+		return nil, fmt.Errorf("image too large: %d bytes (max %d)", len(resp.Body), d.maxBytes)
+	}
+
+	if err := d.writeCache(cachePath, resp.Body); err != nil {
+		// Кэш не критичен — байты всё равно возвращаем
+		d.logger.Warn("Failed to write image cache", "url", imageURL, "file", cachePath, "error", err.Error())
+	}
+
+	d.logger.Debug("Image downloaded", "url", imageURL, "size", len(resp.Body))
+
+	return resp.Body, nil
+}
+
+// cachePath строит путь файла кэша по SHA256 от URL (с подкаталогом по первым 2 символам)
+func (d *Downloader) cachePath(imageURL string) string {
+	sum := sha256.Sum256([]byte(imageURL))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(d.cacheDir, key[:2], key)
+}
+
+// writeCache атомарно записывает файл кэша (через временный файл и rename)
+func (d *Downloader) writeCache(cachePath string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return fsutil.WriteFileAtomic(cachePath, data)
+}
@@ -0,0 +1,127 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/fetcher"
+	"oshcity-news-parser/internal/observability"
+)
+
+var jpeg = []byte{0xFF, 0xD8, 0xFF, 0xE0, 'J', 'F', 'I', 'F'}
+
+// newTestDownloader возвращает загрузчик с кэшем во временном каталоге и сервер миниатюр
+func newTestDownloader(t *testing.T) (*Downloader, *httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			http.NotFound(w, r)
+			return
+		case "/thumb.jpg":
+			requests.Add(1)
+			_, _ = w.Write(jpeg)
+		case "/large.jpg":
+			requests.Add(1)
+			_, _ = w.Write(bytes.Repeat([]byte{0xFF}, 2048))
+		default:
+			requests.Add(1)
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{
+		HTTP:      config.HttpConfig{ConnectTimeoutMS: 5000, TotalTimeoutMS: 5000},
+		RateLimit: config.RateLimitConfig{MaxConcurrentPerHost: 1, RPM: 6000},
+		Images:    config.ImagesConfig{Enabled: true, CacheDir: t.TempDir(), MaxSizeKB: 1},
+	}
+	logger := observability.NewLogger("", "error", 0, 0, 0)
+
+	f, err := fetcher.NewFetcher(cfg, logger)
+	if err != nil {
+		t.Fatalf("NewFetcher error: %v", err)
+	}
+
+	return NewDownloader(cfg, f, logger), server, &requests
+}
+
+func TestDownloadCacheMiss(t *testing.T) {
+	d, server, requests := newTestDownloader(t)
+	imageURL := server.URL + "/thumb.jpg"
+
+	data, err := d.Download(context.Background(), imageURL)
+	if err != nil {
+		t.Fatalf("Download error: %v", err)
+	}
+	if !bytes.Equal(data, jpeg) {
+		t.Errorf("Download = %v, want %v", data, jpeg)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("requests = %d, want 1", requests.Load())
+	}
+
+	cached, err := os.ReadFile(d.cachePath(imageURL))
+	if err != nil {
+		t.Fatalf("cache file not written: %v", err)
+	}
+	if !bytes.Equal(cached, jpeg) {
+		t.Errorf("cached = %v, want %v", cached, jpeg)
+	}
+}
+
+func TestDownloadCacheHit(t *testing.T) {
+	d, server, requests := newTestDownloader(t)
+	imageURL := server.URL + "/missing.jpg"
+
+	// В кэше лежит файл — сервер не запрашивается, даже если там изображения уже нет
+	stored := []byte("cached image")
+	if err := d.writeCache(d.cachePath(imageURL), stored); err != nil {
+		t.Fatalf("writeCache error: %v", err)
+	}
+
+	data, err := d.Download(context.Background(), imageURL)
+	if err != nil {
+		t.Fatalf("Download error: %v", err)
+	}
+	if !bytes.Equal(data, stored) {
+		t.Errorf("Download = %q, want %q", data, stored)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("requests = %d, want 0 on cache hit", requests.Load())
+	}
+}
+
+func TestDownloadErrors(t *testing.T) {
+	d, server, _ := newTestDownloader(t)
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "empty url", url: ""},
+		{name: "not found", url: server.URL + "/missing.jpg"},
+		{name: "too large", url: server.URL + "/large.jpg"},
+	}
+
+	for _, tt := range tests {
+		if _, err := d.Download(context.Background(), tt.url); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+		if tt.url == "" {
+			continue
+		}
+		// Ошибочный ответ не попадает в кэш
+		if _, err := os.Stat(d.cachePath(tt.url)); !os.IsNotExist(err) {
+			t.Errorf("%s: cache file must not be written", tt.name)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"oshcity-news-parser/internal/config"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -49,10 +50,23 @@ func (n *Normalizer) ParseDetailPage(html string) (*ArticleContent, error) {
 		content.ImageURL, _ = doc.Find("article img, .post-content img, .entry-content img").First().Attr("src")
 	}
 
-	// Text: основное тело (article, .post-content, .entry-content)
+	// Date: article:published_time → <time datetime> → мета-блок Elementor
+	publishedTime, _ := doc.Find("meta[property='article:published_time']").Attr("content")
+	if publishedTime != "" {
+		content.DateRaw = publishedTime
+	} else if datetime, exists := doc.Find("time[datetime]").First().Attr("datetime"); exists && datetime != "" {
+		content.DateRaw = datetime
+	} else {
+		content.DateRaw = strings.TrimSpace(doc.Find(".elementor-post-info__item--type-date, .elementor-post-date").First().Text())
+	}
+
+	// Text: основное тело (виджет Elementor, article, .post-content, .entry-content)
 	var textHTML string
+	postWidget := doc.Find(".elementor-widget-theme-post-content").First()
 	article := doc.Find("article").First()
-	if article.Length() > 0 {
+	if postWidget.Length() > 0 {
+		textHTML, _ = postWidget.Html()
+	} else if article.Length() > 0 {
 		textHTML, _ = article.Html()
 	} else {
 		postContent := doc.Find(".post-content, .entry-content, .content").First()
@@ -131,6 +145,10 @@ func (n *Normalizer) TruncatePreview(text string) string {
 
 	// Находим последний пробел перед лимитом
 	truncated := text[:n.cfg.Normalize.MaxPreviewChars]
+	// Не разрезаем многобайтовый символ (кириллица)
+	for !utf8.ValidString(truncated) {
+		truncated = truncated[:len(truncated)-1]
+	}
 	lastSpace := strings.LastIndex(truncated, " ")
 	if lastSpace > 0 {
 		return text[:lastSpace] + "…"
@@ -148,3 +166,13 @@ func NormalizeURL(urlStr string) string {
 	}
 	return urlStr
 }
+
+// HTMLToText извлекает текст из HTML-фрагмента (фид, rendered-HTML REST API):
+// сущности декодируются, пробелы схлопываются
+func HTMLToText(html string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return strings.TrimSpace(html)
+	}
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
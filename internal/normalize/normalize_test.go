@@ -63,3 +63,68 @@ func TestCleanHTML(t *testing.T) {
 		t.Errorf("Multiple spaces not collapsed")
 	}
 }
+
+func TestParseDetailPage(t *testing.T) {
+	cfg := &config.Config{
+		Normalize: config.NormalizeConfig{
+			StripBlocks:    []string{"Похожие"},
+			TrimNBSP:       true,
+			CollapseSpaces: true,
+		},
+	}
+
+	normalizer := NewNormalizer(cfg)
+
+	html := `
+		<html>
+		<head>
+			<meta property="og:title" content="Заголовок новости">
+			<meta property="og:image" content="https://oshcity.gov.kg/wp-content/uploads/photo.jpg">
+			<meta property="article:published_time" content="2025-10-18T09:30:00+06:00">
+		</head>
+		<body>
+			<div class="elementor-widget-theme-post-content">
+				<p>Полный текст новости.</p>
+				<div>Похожие</div>
+			</div>
+		</body>
+		</html>
+	`
+
+	content, err := normalizer.ParseDetailPage(html)
+	if err != nil {
+		t.Fatalf("ParseDetailPage error: %v", err)
+	}
+
+	if content.Title != "Заголовок новости" {
+		t.Errorf("Title = %q", content.Title)
+	}
+	if content.ImageURL != "https://oshcity.gov.kg/wp-content/uploads/photo.jpg" {
+		t.Errorf("ImageURL = %q", content.ImageURL)
+	}
+	if content.DateRaw != "2025-10-18T09:30:00+06:00" {
+		t.Errorf("DateRaw = %q", content.DateRaw)
+	}
+	if content.Text != "Полный текст новости." {
+		t.Errorf("Text = %q", content.Text)
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{name: "plain text", html: "Новость дня", want: "Новость дня"},
+		{name: "tags and whitespace", html: "<p>Первый\n  абзац</p>\n<p>Второй</p>", want: "Первый абзац Второй"},
+		{name: "entities", html: "Мэрия &#171;Ош&#187; &amp; жители&nbsp;города", want: "Мэрия «Ош» & жители города"},
+		{name: "empty", html: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := HTMLToText(tt.html); got != tt.want {
+			t.Errorf("%s: HTMLToText(%q) = %q, want %q", tt.name, tt.html, got, tt.want)
+		}
+	}
+}
@@ -1 +1,178 @@
 package observability
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
+)
+
+// Registry — реестр метрик приложения (без стандартных go_/process_ метрик глобального реестра)
+var Registry = prometheus.NewRegistry()
+
+var (
+	// PagesFetched — загруженные ответы по backend (http|rod) и коду ответа ("error" — без ответа)
+	PagesFetched = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "oshcity",
+		Name:      "pages_fetched_total",
+		Help:      "Fetched responses by backend and status code.",
+	}, []string{"backend", "code"})
+
+	// FetchDuration — длительность одного запроса по backend
+	FetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "oshcity",
+		Name:      "fetch_duration_seconds",
+		Help:      "Duration of a single fetch attempt by backend.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"backend"})
+
+	// FetchRetries — повторные попытки запроса по причине (error|status)
+	FetchRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "oshcity",
+		Name:      "fetch_retries_total",
+		Help:      "Fetch retries by reason.",
+	}, []string{"reason"})
+
+	// CardsParsed — карточки, полученные из листинга / фида / API
+	CardsParsed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "oshcity",
+		Name:      "cards_parsed_total",
+		Help:      "Cards parsed from listing pages, feeds and API.",
+	}, []string{"language"})
+
+	// CardsSkipped — пропущенные карточки по причине (old|date_error)
+	CardsSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "oshcity",
+		Name:      "cards_skipped_total",
+		Help:      "Cards skipped by reason.",
+	}, []string{"language", "reason"})
+
+	// Upserts — результаты сохранения карточек (new|updated|unchanged|failed)
+	Upserts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "oshcity",
+		Name:      "upserts_total",
+		Help:      "Card upserts by result.",
+	}, []string{"language", "result"})
+
+	// HostRateLimit — текущая (адаптивная) скорость запросов к хосту, запросов в минуту
+	HostRateLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "oshcity",
+		Name:      "host_rate_limit_rpm",
+		Help:      "Current adaptive request rate per host, requests per minute.",
+	}, []string{"host"})
+
+	// ProxyUp — прокси в ротации (1) или выведен из неё после ошибок (0)
+	ProxyUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "oshcity",
+		Name:      "proxy_up",
+		Help:      "Whether the outbound proxy is in rotation.",
+	}, []string{"proxy"})
+
+	// RunDuration — длительность прохода по языку
+	RunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "oshcity",
+		Name:      "run_duration_seconds",
+		Help:      "Duration of a pagination run per language.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+	}, []string{"language"})
+)
+
+func init() {
+	Registry.MustRegister(
+		PagesFetched,
+		FetchDuration,
+		FetchRetries,
+		CardsParsed,
+		CardsSkipped,
+		Upserts,
+		HostRateLimit,
+		ProxyUp,
+		RunDuration,
+	)
+}
+
+// StartMetricsServer поднимает HTTP listener с /metrics; останавливается при отмене ctx
+func StartMetricsServer(ctx context.Context, addr string, logger *Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		logger.Info("Metrics server started", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server failed", "addr", addr, "error", err.Error())
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+}
+
+// RunMetricsDumper периодически записывает метрики в файл (для хостов без сбора Prometheus).
+// Последний снимок пишется при отмене ctx.
+func RunMetricsDumper(ctx context.Context, path string, interval time.Duration, logger *Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := DumpMetrics(path); err != nil {
+				logger.Warn("Failed to dump metrics", "path", path, "error", err.Error())
+			}
+			return
+		case <-ticker.C:
+			if err := DumpMetrics(path); err != nil {
+				logger.Warn("Failed to dump metrics", "path", path, "error", err.Error())
+			}
+		}
+	}
+}
+
+// DumpMetrics записывает текущие метрики в текстовом формате Prometheus
+func DumpMetrics(path string) error {
+	families, err := Registry.Gather()
+	if err != nil {
+		return fmt.Errorf("failed to gather metrics: %w", err)
+	}
+
+	var buf bytes.Buffer
+	encoder := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return fmt.Errorf("failed to encode metrics: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	// Через временный файл, чтобы читатель не увидел частично записанный снимок
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to rename metrics file: %w", err)
+	}
+
+	return nil
+}
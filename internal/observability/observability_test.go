@@ -0,0 +1,32 @@
+package observability
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDumpMetrics(t *testing.T) {
+	Upserts.WithLabelValues("ru", "new").Inc()
+	PagesFetched.WithLabelValues("http", "200").Inc()
+
+	path := filepath.Join(t.TempDir(), "logs", "metrics.log")
+	if err := DumpMetrics(path); err != nil {
+		t.Fatalf("DumpMetrics error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+
+	for _, want := range []string{
+		`oshcity_upserts_total{language="ru",result="new"}`,
+		`oshcity_pages_fetched_total{backend="http",code="200"}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("metrics dump does not contain %s", want)
+		}
+	}
+}
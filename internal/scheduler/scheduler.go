@@ -1 +1,137 @@
 package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/observability"
+)
+
+// Job — одна итерация работы планировщика (проход по всем языкам + обновление checksum)
+type Job func(ctx context.Context) error
+
+type Scheduler struct {
+	mode     string
+	interval time.Duration
+	schedule cron.Schedule
+	logger   *observability.Logger
+
+	running atomic.Bool
+	wg      sync.WaitGroup
+
+	mu      sync.RWMutex
+	nextRun time.Time
+}
+
+func NewScheduler(cfg *config.Config, logger *observability.Logger) (*Scheduler, error) {
+	s := &Scheduler{
+		mode:     cfg.Scheduler.Mode,
+		interval: cfg.GetSchedulerInterval(),
+		logger:   logger,
+	}
+
+	switch s.mode {
+	case "oneshot":
+	case "interval":
+		if s.interval <= 0 {
+			return nil, fmt.Errorf("scheduler interval must be > 0")
+		}
+	case "cron":
+		schedule, err := cron.ParseStandard(cfg.Scheduler.CronExpr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", cfg.Scheduler.CronExpr, err)
+		}
+		s.schedule = schedule
+	default:
+		return nil, fmt.Errorf("unsupported scheduler mode: %s", s.mode)
+	}
+
+	return s, nil
+}
+
+// NextRun возвращает время следующего запланированного запуска (zero — если не запланирован)
+func (s *Scheduler) NextRun() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.nextRun
+}
+
+// Run запускает job по расписанию и блокируется до отмены ctx.
+// В режиме oneshot job выполняется один раз синхронно.
+// Если предыдущий запуск ещё не завершён, очередной запуск пропускается.
+func (s *Scheduler) Run(ctx context.Context, job Job) error {
+	if s.mode == "oneshot" {
+		s.logger.Info("Scheduler started", "mode", s.mode)
+		return job(ctx)
+	}
+
+	s.logger.Info("Scheduler started", "mode", s.mode, "interval", s.interval.String())
+
+	// В режиме interval первый запуск выполняем сразу
+	if s.mode == "interval" {
+		s.trigger(ctx, job)
+	}
+
+	for {
+		next := s.next(time.Now())
+		s.setNextRun(next)
+		s.logger.Info("Next run scheduled", "mode", s.mode, "next_run", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.setNextRun(time.Time{})
+			s.logger.Info("Scheduler stopping, waiting for running job")
+			s.wg.Wait()
+			s.logger.Info("Scheduler stopped")
+			return nil
+		case <-timer.C:
+			s.trigger(ctx, job)
+		}
+	}
+}
+
+// trigger запускает job в отдельной горутине, если предыдущий запуск завершён
+func (s *Scheduler) trigger(ctx context.Context, job Job) {
+	if !s.running.CompareAndSwap(false, true) {
+		s.logger.Warn("Previous run still in progress, skipping scheduled run")
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.running.Store(false)
+
+		start := time.Now()
+		s.logger.Info("Scheduled run started")
+
+		if err := job(ctx); err != nil {
+			s.logger.Error("Scheduled run failed", "error", err.Error(), "duration", time.Since(start).String())
+			return
+		}
+
+		s.logger.Info("Scheduled run finished", "duration", time.Since(start).String())
+	}()
+}
+
+// next вычисляет время следующего запуска относительно now
+func (s *Scheduler) next(now time.Time) time.Time {
+	if s.mode == "cron" {
+		return s.schedule.Next(now)
+	}
+	return now.Add(s.interval)
+}
+
+func (s *Scheduler) setNextRun(t time.Time) {
+	s.mu.Lock()
+	s.nextRun = t
+	s.mu.Unlock()
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/observability"
+)
+
+func newTestLogger() *observability.Logger {
+	return observability.NewLogger("", "error", 0, 0, 0)
+}
+
+func TestNewSchedulerCron(t *testing.T) {
+	cfg := &config.Config{
+		Scheduler: config.SchedulerConfig{
+			Mode:     "cron",
+			CronExpr: "*/15 * * * *",
+		},
+	}
+
+	s, err := NewScheduler(cfg, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewScheduler error: %v", err)
+	}
+
+	now := time.Date(2025, 10, 18, 10, 7, 0, 0, time.UTC)
+	expected := time.Date(2025, 10, 18, 10, 15, 0, 0, time.UTC)
+	if next := s.next(now); !next.Equal(expected) {
+		t.Errorf("next(%v) = %v, want %v", now, next, expected)
+	}
+
+	cfg.Scheduler.CronExpr = "invalid"
+	if _, err := NewScheduler(cfg, newTestLogger()); err == nil {
+		t.Errorf("NewScheduler should fail for invalid cron expression")
+	}
+}
+
+func TestSchedulerOneshot(t *testing.T) {
+	cfg := &config.Config{
+		Scheduler: config.SchedulerConfig{Mode: "oneshot"},
+	}
+
+	s, err := NewScheduler(cfg, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewScheduler error: %v", err)
+	}
+
+	var runs atomic.Int32
+	err = s.Run(context.Background(), func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if runs.Load() != 1 {
+		t.Errorf("oneshot runs = %d, want 1", runs.Load())
+	}
+}
+
+func TestSchedulerSkipsOverlappingRuns(t *testing.T) {
+	s := &Scheduler{
+		mode:     "interval",
+		interval: 10 * time.Millisecond,
+		logger:   newTestLogger(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var runs atomic.Int32
+	var concurrent atomic.Int32
+	err := s.Run(ctx, func(ctx context.Context) error {
+		if concurrent.Add(1) > 1 {
+			t.Errorf("overlapping runs detected")
+		}
+		defer concurrent.Add(-1)
+
+		runs.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	// Job длится 50ms при интервале 10ms — за 100ms успевает максимум 2 запуска
+	if runs.Load() < 1 || runs.Load() > 2 {
+		t.Errorf("runs = %d, want 1..2", runs.Load())
+	}
+}
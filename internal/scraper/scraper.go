@@ -311,3 +311,8 @@ func removeLeadingEmoji(s string) string {
 func isCyrillic(r rune) bool {
 	return (r >= 'а' && r <= 'я') || (r >= 'А' && r <= 'Я')
 }
+
+// CardSelector возвращает селектор карточки листинга (признак готовности страницы при рендеринге)
+func (s *Scraper) CardSelector() string {
+	return s.selectors.CardSelectors
+}
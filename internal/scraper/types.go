@@ -1,5 +1,7 @@
 package scraper
 
+import "time"
+
 type Card struct {
 	Title        string
 	URL          string
@@ -7,6 +9,9 @@ type Card struct {
 	Text         string
 	DateRaw      string
 	SequenceNum  int
+
+	// PublishedAt — уже разобранная дата (API/фиды); если zero — дата парсится из DateRaw
+	PublishedAt time.Time
 }
 
 type Selectors struct {
@@ -0,0 +1,102 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxUncompressedSize — лимит распакованного sitemap (протокол sitemaps.org: 50 МБ)
+const maxUncompressedSize = 50 << 20
+
+// URL — запись <url> из urlset
+type URL struct {
+	Loc     string
+	LastMod time.Time // zero — lastmod не указан или не распознан
+}
+
+// Document — разобранный sitemap: список страниц (urlset)
+// или список вложенных sitemap (sitemapindex)
+type Document struct {
+	URLs     []URL
+	Sitemaps []string
+}
+
+type xmlEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+type xmlDocument struct {
+	XMLName  xml.Name
+	URLs     []xmlEntry `xml:"url"`
+	Sitemaps []xmlEntry `xml:"sitemap"`
+}
+
+// lastModLayouts — форматы W3C Datetime, допустимые в lastmod
+var lastModLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02",
+}
+
+// Parse разбирает sitemap (urlset или sitemapindex), в том числе сжатый gzip
+func Parse(body []byte) (*Document, error) {
+	// .xml.gz отдаётся как есть, без Content-Encoding
+	if len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip sitemap: %w", err)
+		}
+		body, err = io.ReadAll(io.LimitReader(reader, maxUncompressedSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+	}
+
+	var raw xmlDocument
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+
+	doc := &Document{}
+	switch raw.XMLName.Local {
+	case "urlset":
+		for _, entry := range raw.URLs {
+			loc := strings.TrimSpace(entry.Loc)
+			if loc == "" {
+				continue
+			}
+			doc.URLs = append(doc.URLs, URL{
+				Loc:     loc,
+				LastMod: parseLastMod(entry.LastMod),
+			})
+		}
+	case "sitemapindex":
+		for _, entry := range raw.Sitemaps {
+			if loc := strings.TrimSpace(entry.Loc); loc != "" {
+				doc.Sitemaps = append(doc.Sitemaps, loc)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported sitemap root element: %s", raw.XMLName.Local)
+	}
+
+	return doc, nil
+}
+
+func parseLastMod(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range lastModLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
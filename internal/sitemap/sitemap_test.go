@@ -0,0 +1,90 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+)
+
+const testURLSet = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url>
+		<loc>https://oshcity.gov.kg/ru/novosti/first/</loc>
+		<lastmod>2025-10-18T14:30:00+06:00</lastmod>
+	</url>
+	<url>
+		<loc> https://oshcity.gov.kg/ru/novosti/second/ </loc>
+		<lastmod>2025-10-17</lastmod>
+	</url>
+	<url>
+		<loc>https://oshcity.gov.kg/ru/novosti/third/</loc>
+	</url>
+</urlset>`
+
+const testIndex = `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>https://oshcity.gov.kg/post-sitemap1.xml</loc></sitemap>
+	<sitemap><loc>https://oshcity.gov.kg/post-sitemap2.xml</loc><lastmod>2025-10-18</lastmod></sitemap>
+</sitemapindex>`
+
+func TestParseURLSet(t *testing.T) {
+	doc, err := Parse([]byte(testURLSet))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(doc.URLs) != 3 || len(doc.Sitemaps) != 0 {
+		t.Fatalf("got %d urls, %d sitemaps, want 3, 0", len(doc.URLs), len(doc.Sitemaps))
+	}
+
+	tests := []struct {
+		loc     string
+		lastMod time.Time
+	}{
+		{"https://oshcity.gov.kg/ru/novosti/first/", time.Date(2025, 10, 18, 8, 30, 0, 0, time.UTC)},
+		{"https://oshcity.gov.kg/ru/novosti/second/", time.Date(2025, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"https://oshcity.gov.kg/ru/novosti/third/", time.Time{}},
+	}
+	for i, tt := range tests {
+		if doc.URLs[i].Loc != tt.loc {
+			t.Errorf("URLs[%d].Loc = %q, want %q", i, doc.URLs[i].Loc, tt.loc)
+		}
+		if !doc.URLs[i].LastMod.Equal(tt.lastMod) {
+			t.Errorf("URLs[%d].LastMod = %v, want %v", i, doc.URLs[i].LastMod, tt.lastMod)
+		}
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	doc, err := Parse([]byte(testIndex))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(doc.Sitemaps) != 2 || len(doc.URLs) != 0 {
+		t.Fatalf("got %d sitemaps, %d urls, want 2, 0", len(doc.Sitemaps), len(doc.URLs))
+	}
+	if doc.Sitemaps[1] != "https://oshcity.gov.kg/post-sitemap2.xml" {
+		t.Errorf("Sitemaps[1] = %q", doc.Sitemaps[1])
+	}
+}
+
+func TestParseGzip(t *testing.T) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, _ = writer.Write([]byte(testURLSet))
+	_ = writer.Close()
+
+	doc, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(doc.URLs) != 3 {
+		t.Errorf("len(URLs) = %d, want 3", len(doc.URLs))
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	if _, err := Parse([]byte(`<html></html>`)); err == nil {
+		t.Error("expected error for non-sitemap document")
+	}
+}
@@ -0,0 +1,276 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"oshcity-news-parser/internal/storage"
+)
+
+// Repository — эталонная реализация storage.Repository в памяти.
+// Используется в тестах: драйверы БД проверяются общим набором storagetest на тех же ожиданиях
+type Repository struct {
+	mu        sync.RWMutex
+	languages map[string]bool
+	news      map[string]storage.ArticleCard // по CanonicalURL
+	revisions map[string][]storage.Revision  // по CanonicalURL, старые первыми
+	updatedAt map[string]time.Time           // по CanonicalURL: последняя запись карточки
+	checksums map[string]string              // агрегированная сумма по языку
+	runs      []storage.RunRecord
+}
+
+// NewRepository создаёт пустое хранилище с зарегистрированными языками
+func NewRepository(languages ...string) *Repository {
+	r := &Repository{
+		languages: make(map[string]bool),
+		news:      make(map[string]storage.ArticleCard),
+		revisions: make(map[string][]storage.Revision),
+		updatedAt: make(map[string]time.Time),
+		checksums: make(map[string]string),
+	}
+	for _, alias := range languages {
+		r.languages[alias] = true
+	}
+	return r
+}
+
+// RegisterLanguages добавляет языки в справочник
+func (r *Repository) RegisterLanguages(_ context.Context, languages []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, alias := range languages {
+		r.languages[alias] = true
+	}
+	return nil
+}
+
+// UpsertCard сохраняет или обновляет карточку
+func (r *Repository) UpsertCard(ctx context.Context, card *storage.ArticleCard) (storage.UpsertResult, error) {
+	results, err := r.UpsertCards(ctx, []*storage.ArticleCard{card})
+	if err != nil {
+		return storage.UpsertUnchanged, err
+	}
+	return results[0], nil
+}
+
+// UpsertCards сохраняет карточки атомарно: при ошибке ни одна не сохраняется
+func (r *Repository) UpsertCards(_ context.Context, cards []*storage.ArticleCard) ([]storage.UpsertResult, error) {
+	if len(cards) == 0 {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Проверяем пакет до записи — аналог отката транзакции
+	for _, card := range cards {
+		if !r.languages[card.Language] {
+			return nil, fmt.Errorf("language not found: %s", card.Language)
+		}
+	}
+
+	results := make([]storage.UpsertResult, 0, len(cards))
+	for _, card := range cards {
+		results = append(results, r.upsert(card))
+	}
+
+	return results, nil
+}
+
+// upsert сохраняет одну карточку; вызывается под r.mu
+func (r *Repository) upsert(card *storage.ArticleCard) storage.UpsertResult {
+	stored := *card
+	stored.ImageData = append([]byte(nil), card.ImageData...)
+
+	previous, ok := r.news[card.CanonicalURL]
+	if !ok {
+		r.news[card.CanonicalURL] = stored
+		r.updatedAt[card.CanonicalURL] = time.Now().UTC()
+		return storage.UpsertInserted
+	}
+	if previous.CheckSum == card.CheckSum {
+		return storage.UpsertUnchanged
+	}
+
+	// Сдвиг карточки в листинге или новые байты миниатюры меняют CheckSum,
+	// но не содержимое — строка обновляется без ревизии
+	result := storage.UpsertUnchanged
+	if contentChanged(&previous, card) {
+		result = storage.UpsertUpdated
+		r.revisions[card.CanonicalURL] = append(r.revisions[card.CanonicalURL], storage.Revision{
+			URL:        previous.CanonicalURL,
+			Title:      previous.Title,
+			Text:       previous.Text,
+			ImageURL:   previous.ImageURL,
+			CheckSum:   previous.CheckSum,
+			ObservedAt: time.Now().UTC(),
+		})
+	}
+
+	// Поля детальной страницы (и превью из них) не затираются, если в карточке они пустые
+	if stored.Text == "" {
+		stored.Text = previous.Text
+	}
+	if stored.Body == "" {
+		stored.Body = previous.Body
+	}
+	if stored.OgImageURL == "" {
+		stored.OgImageURL = previous.OgImageURL
+	}
+	if stored.DetailDate.IsZero() {
+		stored.DetailDate = previous.DetailDate
+	}
+	if stored.ImageData == nil {
+		stored.ImageData = previous.ImageData
+	}
+
+	r.news[card.CanonicalURL] = stored
+	r.updatedAt[card.CanonicalURL] = time.Now().UTC()
+	return result
+}
+
+// contentChanged сообщает, изменились ли поля, по которым ведутся ревизии (пустой Text — не изменение)
+func contentChanged(previous, card *storage.ArticleCard) bool {
+	textChanged := card.Text != "" && previous.Text != card.Text
+	return previous.Title != card.Title || textChanged || previous.ImageURL != card.ImageURL
+}
+
+// ExistsByURL проверяет наличие карточки по URL
+func (r *Repository) ExistsByURL(_ context.Context, url string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.news[url]
+	return ok, nil
+}
+
+// GetUpdatedAt возвращает время последней записи карточки по URL
+func (r *Repository) GetUpdatedAt(_ context.Context, url string) (time.Time, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	updatedAt, ok := r.updatedAt[url]
+	return updatedAt, ok, nil
+}
+
+// GetLatestKnownDate получает последнюю загруженную дату для языка
+func (r *Repository) GetLatestKnownDate(_ context.Context, lang string) (time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.languages[lang] {
+		return time.Time{}, fmt.Errorf("language not found: %s", lang)
+	}
+
+	var latest time.Time
+	for _, card := range r.news {
+		if card.Language == lang && card.Date.After(latest) {
+			latest = card.Date
+		}
+	}
+
+	if latest.IsZero() {
+		// Если нет данных, возвращаем время 1 года назад
+		return time.Now().UTC().AddDate(-1, 0, 0), nil
+	}
+
+	return latest, nil
+}
+
+// GetCardCount получает количество загруженных карточек для языка
+func (r *Repository) GetCardCount(_ context.Context, lang string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.languages[lang] {
+		return 0, fmt.Errorf("language not found: %s", lang)
+	}
+
+	count := 0
+	for _, card := range r.news {
+		if card.Language == lang {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// UpdateNewsCheckSum пересчитывает агрегированную контрольную сумму по каждому языку
+// (конкатенация check_sum в порядке dt, url — как в драйверах БД)
+func (r *Repository) UpdateNewsCheckSum(_ context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byLanguage := make(map[string][]storage.ArticleCard)
+	for _, card := range r.news {
+		byLanguage[card.Language] = append(byLanguage[card.Language], card)
+	}
+
+	for lang, cards := range byLanguage {
+		sort.Slice(cards, func(i, j int) bool {
+			if !cards[i].Date.Equal(cards[j].Date) {
+				return cards[i].Date.Before(cards[j].Date)
+			}
+			return cards[i].CanonicalURL < cards[j].CanonicalURL
+		})
+
+		var sums strings.Builder
+		for _, card := range cards {
+			sums.WriteString(card.CheckSum)
+		}
+		sum := sha256.Sum256([]byte(sums.String()))
+		r.checksums[lang] = hex.EncodeToString(sum[:])
+	}
+
+	return fmt.Sprintf("checksums updated for %d languages", len(byLanguage)), nil
+}
+
+// ListRevisions возвращает предыдущие версии карточки по URL (новые первыми)
+func (r *Repository) ListRevisions(_ context.Context, url string) ([]storage.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.revisions[url]
+	revisions := make([]storage.Revision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		revisions = append(revisions, stored[i])
+	}
+	return revisions, nil
+}
+
+// RecordRun сохраняет запись о проходе в историю запусков
+func (r *Repository) RecordRun(_ context.Context, run *storage.RunRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.languages[run.Language] {
+		return fmt.Errorf("language not found: %s", run.Language)
+	}
+	r.runs = append(r.runs, *run)
+	return nil
+}
+
+// Runs возвращает сохранённую историю запусков (для проверок в тестах)
+func (r *Repository) Runs() []storage.RunRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]storage.RunRecord(nil), r.runs...)
+}
+
+// Ping всегда успешен
+func (r *Repository) Ping(_ context.Context) error {
+	return nil
+}
+
+// Close ничего не освобождает; нужен для совместимости с драйверами БД
+func (r *Repository) Close() error {
+	return nil
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"oshcity-news-parser/internal/storage"
+	"oshcity-news-parser/internal/storage/storagetest"
+)
+
+func TestContract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Repository {
+		return NewRepository("ru", "kg")
+	})
+}
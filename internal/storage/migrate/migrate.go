@@ -0,0 +1,320 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"oshcity-news-parser/internal/observability"
+)
+
+// ErrSchemaBehind — версия схемы БД ниже, чем ожидает бинарник
+var ErrSchemaBehind = errors.New("database schema is behind the binary")
+
+// migrationFileRe — имя файла миграции: 0001_create_news.sql
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.sql$`)
+
+// Migration — одна версионированная миграция
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Status — состояние миграции в БД
+type Status struct {
+	Migration
+	AppliedAt time.Time // zero — не применена
+}
+
+// Dialect — SQL таблицы версий для конкретного драйвера
+type Dialect struct {
+	Name           string
+	CreateTableSQL string
+	ExistsSQL      string // 1, если таблица версий есть, иначе 0
+	SelectSQL      string // version, applied_at по возрастанию версии
+	InsertSQL      string // параметры: version, name
+	BatchSeparator *regexp.Regexp
+}
+
+var (
+	// MSSQL — миграции разделяются на батчи строками GO (как в SSMS/sqlcmd)
+	MSSQL = Dialect{
+		Name: "mssql",
+		CreateTableSQL: `
+			IF OBJECT_ID(N'dbo.TblSchemaMigrations', N'U') IS NULL
+			CREATE TABLE dbo.TblSchemaMigrations (
+				[Version]   INT NOT NULL PRIMARY KEY,
+				[Name]      NVARCHAR(256) NOT NULL,
+				[AppliedDT] DATETIME2 NOT NULL DEFAULT SYSUTCDATETIME()
+			)`,
+		ExistsSQL:      `SELECT CASE WHEN OBJECT_ID(N'dbo.TblSchemaMigrations', N'U') IS NULL THEN 0 ELSE 1 END`,
+		SelectSQL:      `SELECT [Version], [AppliedDT] FROM dbo.TblSchemaMigrations ORDER BY [Version]`,
+		InsertSQL:      `INSERT INTO dbo.TblSchemaMigrations ([Version], [Name]) VALUES (@p1, @p2)`,
+		BatchSeparator: regexp.MustCompile(`(?im)^\s*GO\s*$`),
+	}
+
+	// Postgres — файл миграции выполняется одним запросом
+	Postgres = Dialect{
+		Name: "postgres",
+		CreateTableSQL: `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version    INT PRIMARY KEY,
+				name       VARCHAR(256) NOT NULL,
+				applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+			)`,
+		ExistsSQL: `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_migrations'`,
+		SelectSQL: `SELECT version, applied_at FROM schema_migrations ORDER BY version`,
+		InsertSQL: `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+	}
+
+	// SQLite — файл миграции выполняется одним запросом (несколько операторов через ;)
+	SQLite = Dialect{
+		Name: "sqlite",
+		CreateTableSQL: `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version    INTEGER PRIMARY KEY,
+				name       TEXT NOT NULL,
+				applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`,
+		ExistsSQL: `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`,
+		SelectSQL: `SELECT version, applied_at FROM schema_migrations ORDER BY version`,
+		InsertSQL: `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`,
+	}
+)
+
+// Runner применяет встроенные миграции к БД
+type Runner struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+	logger     *observability.Logger
+}
+
+// NewRunner загружает миграции *.sql из каталога dir файловой системы fsys (обычно embed.FS)
+func NewRunner(db *sql.DB, dialect Dialect, fsys fs.FS, dir string, logger *observability.Logger) (*Runner, error) {
+	migrations, err := Load(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Runner{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+		logger:     logger,
+	}, nil
+}
+
+// Load читает миграции и сортирует их по версии
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    match[2],
+			SQL:     string(data),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// LatestVersion — версия схемы, которую ожидает бинарник
+func (r *Runner) LatestVersion() int {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// Status возвращает все миграции с отметкой о применении. БД не меняется:
+// без таблицы версий все миграции считаются неприменёнными
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(r.migrations))
+	for _, migration := range r.migrations {
+		statuses = append(statuses, Status{
+			Migration: migration,
+			AppliedAt: applied[migration.Version],
+		})
+	}
+	return statuses, nil
+}
+
+// CurrentVersion возвращает максимальную применённую версию (0 — схема пустая или нет таблицы версий)
+func (r *Runner) CurrentVersion(ctx context.Context) (int, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	current := 0
+	for version := range applied {
+		current = max(current, version)
+	}
+	return current, nil
+}
+
+// Check возвращает ErrSchemaBehind, если в БД применены не все миграции бинарника
+func (r *Runner) Check(ctx context.Context) error {
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, status := range statuses {
+		if status.AppliedAt.IsZero() {
+			pending = append(pending, fmt.Sprintf("%04d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s (run 'migrate up')", ErrSchemaBehind, strings.Join(pending, ", "))
+	}
+
+	return nil
+}
+
+// Up создаёт таблицу версий при необходимости и применяет все неприменённые миграции
+// по порядку, каждую в своей транзакции
+func (r *Runner) Up(ctx context.Context) (int, error) {
+	if _, err := r.db.ExecContext(ctx, r.dialect.CreateTableSQL); err != nil {
+		return 0, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, status := range statuses {
+		if !status.AppliedAt.IsZero() {
+			continue
+		}
+
+		r.logger.Info("Applying migration",
+			"driver", r.dialect.Name,
+			"version", status.Version,
+			"name", status.Name,
+		)
+
+		if err := r.apply(ctx, status.Migration); err != nil {
+			return count, fmt.Errorf("failed to apply migration %04d_%s: %w", status.Version, status.Name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+func (r *Runner) apply(ctx context.Context, migration Migration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		// После Commit откат вернёт sql.ErrTxDone — это ожидаемо
+		_ = tx.Rollback()
+	}()
+
+	for _, batch := range r.batches(migration.SQL) {
+		if _, err := tx.ExecContext(ctx, batch); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, r.dialect.InsertSQL, migration.Version, migration.Name); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// batches делит миграцию на батчи по разделителю диалекта (пустые батчи пропускаются)
+func (r *Runner) batches(sqlText string) []string {
+	parts := []string{sqlText}
+	if r.dialect.BatchSeparator != nil {
+		parts = r.dialect.BatchSeparator.Split(sqlText, -1)
+	}
+
+	batches := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if strings.TrimSpace(part) != "" {
+			batches = append(batches, part)
+		}
+	}
+	return batches
+}
+
+// applied возвращает применённые версии; таблицы версий нет — ни одной (версия 0)
+func (r *Runner) applied(ctx context.Context) (map[int]time.Time, error) {
+	var exists int
+	if err := r.db.QueryRowContext(ctx, r.dialect.ExistsSQL).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check migrations table: %w", err)
+	}
+	if exists == 0 {
+		return map[int]time.Time{}, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.SelectSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query migrations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan migration: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,128 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+
+	"oshcity-news-parser/internal/observability"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0002_add_column.sql": {Data: []byte("ALTER TABLE t ADD c INT;")},
+		"migrations/0001_baseline.sql":   {Data: []byte("CREATE TABLE t (id INT);")},
+	}
+
+	migrations, err := Load(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("len(migrations) = %d, want 2", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "baseline" {
+		t.Errorf("migrations[0] = %d_%s, want 1_baseline", migrations[0].Version, migrations[0].Name)
+	}
+	if migrations[1].Version != 2 {
+		t.Errorf("migrations[1].Version = %d, want 2", migrations[1].Version)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "bad file name",
+			fsys: fstest.MapFS{"migrations/baseline.sql": {Data: []byte("SELECT 1")}},
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"migrations/0001_a.sql":  {Data: []byte("SELECT 1")},
+				"migrations/001_b.sql":   {Data: []byte("SELECT 2")},
+				"migrations/0002_ok.sql": {Data: []byte("SELECT 3")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if _, err := Load(tt.fsys, "migrations"); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestBatches(t *testing.T) {
+	runner := &Runner{dialect: MSSQL}
+	batches := runner.batches("CREATE TABLE t (id INT);\nGO\n\n  go  \nCREATE INDEX ix ON t (id);\nGO\n")
+	if len(batches) != 2 {
+		t.Fatalf("len(batches) = %d, want 2: %q", len(batches), batches)
+	}
+
+	runner = &Runner{dialect: Postgres}
+	if batches := runner.batches("CREATE TABLE t (id INT);\nGO\n"); len(batches) != 1 {
+		t.Errorf("postgres: len(batches) = %d, want 1", len(batches))
+	}
+}
+
+func TestStatusIsReadOnly(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sql.Open error: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	fsys := fstest.MapFS{
+		"migrations/0001_baseline.sql": {Data: []byte("CREATE TABLE t (id INT);")},
+	}
+	runner, err := NewRunner(db, SQLite, fsys, "migrations", observability.NewLogger("", "error", 0, 0, 0))
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+
+	ctx := context.Background()
+	tableExists := func() bool {
+		var count int
+		if err := db.QueryRowContext(ctx, SQLite.ExistsSQL).Scan(&count); err != nil {
+			t.Fatalf("failed to check migrations table: %v", err)
+		}
+		return count > 0
+	}
+
+	// Без таблицы версий схема считается пустой, а Status, CurrentVersion и Check её не создают
+	statuses, err := runner.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status error: %v", err)
+	}
+	if len(statuses) != 1 || !statuses[0].AppliedAt.IsZero() {
+		t.Errorf("statuses = %+v, want one pending migration", statuses)
+	}
+	if current, err := runner.CurrentVersion(ctx); err != nil || current != 0 {
+		t.Errorf("CurrentVersion = %d, %v; want 0", current, err)
+	}
+	if err := runner.Check(ctx); !errors.Is(err, ErrSchemaBehind) {
+		t.Errorf("Check error = %v, want ErrSchemaBehind", err)
+	}
+	if tableExists() {
+		t.Errorf("migrations table created by read-only calls")
+	}
+
+	// Таблицу версий создаёт только Up
+	if applied, err := runner.Up(ctx); err != nil || applied != 1 {
+		t.Fatalf("Up = %d, %v; want 1 migration applied", applied, err)
+	}
+	if !tableExists() {
+		t.Errorf("migrations table not created by Up")
+	}
+	if err := runner.Check(ctx); err != nil {
+		t.Errorf("Check after Up error: %v", err)
+	}
+}
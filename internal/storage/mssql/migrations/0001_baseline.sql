@@ -0,0 +1,66 @@
+-- Базовая схема. Объекты создаются только если их ещё нет,
+-- поэтому миграция безопасно применяется к уже работающей БД.
+
+IF OBJECT_ID(N'dbo.TblRefLanguages', N'U') IS NULL
+CREATE TABLE dbo.TblRefLanguages (
+	[UID]   INT IDENTITY(1, 1) NOT NULL PRIMARY KEY,
+	[Alias] NVARCHAR(10) NOT NULL UNIQUE
+);
+
+IF OBJECT_ID(N'dbo.TblNews', N'U') IS NULL
+CREATE TABLE dbo.TblNews (
+	[UID]          BIGINT IDENTITY(1, 1) NOT NULL PRIMARY KEY,
+	[Language_UID] INT NOT NULL REFERENCES dbo.TblRefLanguages ([UID]),
+	[SequenceNum]  INT NOT NULL DEFAULT 0,
+	[DT]           DATETIME2 NOT NULL,
+	[Title]        NVARCHAR(MAX) NOT NULL,
+	[Text]         NVARCHAR(MAX) NOT NULL,
+	[URL]          NVARCHAR(850) NOT NULL UNIQUE,
+	[ThumbnailURL] NVARCHAR(2048) NULL,
+	[CheckSum]     NVARCHAR(320) NULL
+);
+
+IF OBJECT_ID(N'dbo.TblNewsCheckSums', N'U') IS NULL
+CREATE TABLE dbo.TblNewsCheckSums (
+	[Language_UID] INT NOT NULL PRIMARY KEY REFERENCES dbo.TblRefLanguages ([UID]),
+	[NewsCount]    INT NOT NULL,
+	[CheckSum]     CHAR(64) NOT NULL,
+	[UpdatedDT]    DATETIME2 NOT NULL DEFAULT SYSUTCDATETIME()
+);
+GO
+
+-- Процедура создаётся только если её нет: существующая реализация не перезаписывается
+IF OBJECT_ID(N'dbo.USP_UpdateNewsCheckSum', N'P') IS NULL
+EXEC(N'
+CREATE PROCEDURE dbo.USP_UpdateNewsCheckSum
+	@result INT OUTPUT,
+	@Msg NVARCHAR(4000) OUTPUT
+AS
+BEGIN
+	SET NOCOUNT ON;
+
+	BEGIN TRY
+		MERGE dbo.TblNewsCheckSums AS target
+		USING (
+			SELECT
+				[Language_UID],
+				COUNT(*) AS [NewsCount],
+				CONVERT(CHAR(64), HASHBYTES(''SHA2_256'', STRING_AGG(CAST([CheckSum] AS NVARCHAR(MAX)), N'''') WITHIN GROUP (ORDER BY [DT], [URL])), 2) AS [CheckSum]
+			FROM dbo.TblNews
+			GROUP BY [Language_UID]
+		) AS source
+		ON target.[Language_UID] = source.[Language_UID]
+		WHEN MATCHED THEN
+			UPDATE SET [NewsCount] = source.[NewsCount], [CheckSum] = source.[CheckSum], [UpdatedDT] = SYSUTCDATETIME()
+		WHEN NOT MATCHED THEN
+			INSERT ([Language_UID], [NewsCount], [CheckSum]) VALUES (source.[Language_UID], source.[NewsCount], source.[CheckSum]);
+
+		SET @result = @@ROWCOUNT;
+		SET @Msg = CONCAT(N''checksums updated for '', @result, N'' languages'');
+	END TRY
+	BEGIN CATCH
+		SET @result = -1;
+		SET @Msg = ERROR_MESSAGE();
+	END CATCH
+END
+');
@@ -0,0 +1,17 @@
+-- Поля детальной страницы, байты миниатюры и время последней записи карточки
+
+IF COL_LENGTH(N'dbo.TblNews', N'Body') IS NULL
+	ALTER TABLE dbo.TblNews ADD [Body] NVARCHAR(MAX) NULL;
+
+IF COL_LENGTH(N'dbo.TblNews', N'OgImageURL') IS NULL
+	ALTER TABLE dbo.TblNews ADD [OgImageURL] NVARCHAR(2048) NULL;
+
+IF COL_LENGTH(N'dbo.TblNews', N'DetailDT') IS NULL
+	ALTER TABLE dbo.TblNews ADD [DetailDT] DATETIME2 NULL;
+
+IF COL_LENGTH(N'dbo.TblNews', N'ThumbnailData') IS NULL
+	ALTER TABLE dbo.TblNews ADD [ThumbnailData] VARBINARY(MAX) NULL;
+
+-- Без значения по умолчанию: у существующих строк время записи неизвестно (NULL)
+IF COL_LENGTH(N'dbo.TblNews', N'UpdatedDT') IS NULL
+	ALTER TABLE dbo.TblNews ADD [UpdatedDT] DATETIME2 NULL;
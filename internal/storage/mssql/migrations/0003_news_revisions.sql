@@ -0,0 +1,16 @@
+-- Предыдущие версии карточек при изменении CheckSum
+
+IF OBJECT_ID(N'dbo.TblNewsRevisions', N'U') IS NULL
+CREATE TABLE dbo.TblNewsRevisions (
+	[UID]          BIGINT IDENTITY(1, 1) NOT NULL PRIMARY KEY,
+	[URL]          NVARCHAR(850) NOT NULL,
+	[Title]        NVARCHAR(MAX) NULL,
+	[Text]         NVARCHAR(MAX) NULL,
+	[ThumbnailURL] NVARCHAR(2048) NULL,
+	[CheckSum]     NVARCHAR(320) NULL,
+	[ObservedDT]   DATETIME2 NOT NULL
+);
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE [name] = N'IX_TblNewsRevisions_URL' AND [object_id] = OBJECT_ID(N'dbo.TblNewsRevisions'))
+	CREATE INDEX IX_TblNewsRevisions_URL ON dbo.TblNewsRevisions ([URL], [ObservedDT] DESC);
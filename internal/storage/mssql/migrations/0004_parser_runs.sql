@@ -0,0 +1,22 @@
+-- История запусков парсера
+
+IF OBJECT_ID(N'dbo.TblParserRuns', N'U') IS NULL
+CREATE TABLE dbo.TblParserRuns (
+	[UID]            BIGINT IDENTITY(1, 1) NOT NULL PRIMARY KEY,
+	[Language_UID]   INT NOT NULL REFERENCES dbo.TblRefLanguages ([UID]),
+	[StartedDT]      DATETIME2 NOT NULL,
+	[FinishedDT]     DATETIME2 NOT NULL,
+	[TotalPages]     INT NOT NULL,
+	[TotalCards]     INT NOT NULL,
+	[OldCards]       INT NOT NULL,
+	[CachedPages]    INT NOT NULL,
+	[NewCards]       INT NOT NULL,
+	[UpdatedCards]   INT NOT NULL,
+	[UnchangedCards] INT NOT NULL DEFAULT 0,
+	[StoppedReason]  NVARCHAR(1024) NULL,
+	[ErrorText]      NVARCHAR(MAX) NULL
+);
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE [name] = N'IX_TblParserRuns_Language_Started' AND [object_id] = OBJECT_ID(N'dbo.TblParserRuns'))
+	CREATE INDEX IX_TblParserRuns_Language_Started ON dbo.TblParserRuns ([Language_UID], [StartedDT] DESC);
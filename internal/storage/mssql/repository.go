@@ -3,20 +3,104 @@ package mssql
 import (
 	"context"
 	"database/sql"
+	"embed"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/microsoft/go-mssqldb"
 
 	"oshcity-news-parser/internal/observability"
 	"oshcity-news-parser/internal/storage"
+	"oshcity-news-parser/internal/storage/migrate"
 )
 
+// migrationsFS — версионированные миграции схемы (см. migrate.Runner)
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// maxMergeCards — карточек в одном MERGE: 12 параметров на карточку при лимите SQL Server в 2100
+const maxMergeCards = 150
+
+// upsertCardsQuery — MERGE пакета из n карточек в TblNews одним запросом.
+// Параметры карточки i — @<Имя><i> (см. upsertArgs); source.[Idx] сопоставляет строки OUTPUT с карточками.
+// Поля детальной страницы и Text не затираются, если в карточке они пустые.
+// Колонки детальной страницы и UpdatedDT создаёт миграция 0002_detail_columns, TblNewsRevisions — 0003_news_revisions;
+// запуск с непримененными миграциями останавливается проверкой migrate.Runner.Check
+func upsertCardsQuery(n int) string {
+	var rows strings.Builder
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			rows.WriteString(",\n\t\t")
+		}
+		fmt.Fprintf(&rows, "(%[1]d, @LanguageUID%[1]d, @SequenceNum%[1]d, @DT%[1]d, @Title%[1]d, @Text%[1]d, @URL%[1]d, @ThumbnailURL%[1]d, "+
+			"@CheckSum%[1]d, @Body%[1]d, @OgImageURL%[1]d, @DetailDT%[1]d, CAST(@ThumbnailData%[1]d AS VARBINARY(MAX)))", i)
+	}
+
+	return `
+	SET NOCOUNT ON;
+
+	DECLARE @Changes TABLE (
+		[Idx]          INT,
+		[Action]       NVARCHAR(10),
+		[URL]          NVARCHAR(850),
+		[Title]        NVARCHAR(MAX),
+		[Text]         NVARCHAR(MAX),
+		[ThumbnailURL] NVARCHAR(2048),
+		[CheckSum]     NVARCHAR(320),
+		[Changed]      BIT
+	);
+
+	MERGE INTO TblNews AS target
+	USING (VALUES
+		` + rows.String() + `
+	) AS source ([Idx], [LanguageUID], [SequenceNum], [DT], [Title], [Text], [URL], [ThumbnailURL], [CheckSum], [Body], [OgImageURL], [DetailDT], [ThumbnailData])
+	ON target.[URL] = source.[URL]
+	WHEN MATCHED AND (target.[CheckSum] IS NULL OR target.[CheckSum] <> source.[CheckSum]) THEN
+		UPDATE SET
+			[Title] = source.[Title],
+			[Text] = COALESCE(NULLIF(source.[Text], N''), target.[Text]),
+			[ThumbnailURL] = source.[ThumbnailURL],
+			[DT] = source.[DT],
+			[CheckSum] = source.[CheckSum],
+			[SequenceNum] = source.[SequenceNum],
+			[Body] = COALESCE(NULLIF(source.[Body], N''), target.[Body]),
+			[OgImageURL] = COALESCE(NULLIF(source.[OgImageURL], N''), target.[OgImageURL]),
+			[DetailDT] = COALESCE(source.[DetailDT], target.[DetailDT]),
+			[ThumbnailData] = COALESCE(source.[ThumbnailData], target.[ThumbnailData]),
+			[UpdatedDT] = SYSUTCDATETIME()
+	WHEN NOT MATCHED THEN
+		INSERT ([Language_UID], [SequenceNum], [DT], [Title], [Text], [URL], [ThumbnailURL], [CheckSum], [Body], [OgImageURL], [DetailDT], [ThumbnailData], [UpdatedDT])
+		VALUES (source.[LanguageUID], source.[SequenceNum], source.[DT], source.[Title], source.[Text], source.[URL], source.[ThumbnailURL], source.[CheckSum],
+			NULLIF(source.[Body], N''), NULLIF(source.[OgImageURL], N''), source.[DetailDT], source.[ThumbnailData], SYSUTCDATETIME())
+	OUTPUT source.[Idx], $action, source.[URL], deleted.[Title], deleted.[Text], deleted.[ThumbnailURL], deleted.[CheckSum],
+		CASE WHEN ISNULL(deleted.[Title], N'') <> ISNULL(source.[Title], N'')
+			OR (ISNULL(source.[Text], N'') <> N'' AND ISNULL(deleted.[Text], N'') <> source.[Text])
+			OR ISNULL(deleted.[ThumbnailURL], N'') <> ISNULL(source.[ThumbnailURL], N'')
+			THEN 1 ELSE 0 END
+	INTO @Changes;
+
+	INSERT INTO TblNewsRevisions ([URL], [Title], [Text], [ThumbnailURL], [CheckSum], [ObservedDT])
+	SELECT [URL], [Title], [Text], [ThumbnailURL], [CheckSum], SYSUTCDATETIME()
+	FROM @Changes
+	WHERE [Action] = 'UPDATE' AND [Changed] = 1;
+
+	SELECT [Idx], [Action], [Changed] FROM @Changes;
+`
+}
+
 type Repository struct {
 	db             *sql.DB
 	commandTimeout time.Duration
 	logger         *observability.Logger
+
+	// Кэш Language_UID по Alias — справочник языков не меняется во время работы
+	langMu   sync.RWMutex
+	langUIDs map[string]int
 }
 
 func NewRepository(
@@ -51,89 +135,189 @@ func NewRepository(
 		db:             db,
 		commandTimeout: time.Duration(commandTimeoutMS) * time.Millisecond,
 		logger:         logger,
+		langUIDs:       make(map[string]int),
 	}, nil
 }
 
-// UpsertCard сохраняет или обновляет карточку
-func (r *Repository) UpsertCard(ctx context.Context, card *storage.ArticleCard) (isNew bool, isUpdated bool, err error) {
+// Migrator возвращает runner встроенных миграций схемы
+func (r *Repository) Migrator() (*migrate.Runner, error) {
+	return migrate.NewRunner(r.db, migrate.MSSQL, migrationsFS, "migrations", r.logger)
+}
+
+// RegisterLanguages добавляет языки из конфига в TblRefLanguages
+func (r *Repository) RegisterLanguages(ctx context.Context, languages []string) error {
 	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
 	defer cancel()
 
-	// MERGE statement для MS SQL
 	query := `
-		MERGE INTO TblNews AS target
-		USING (SELECT @URL AS URL) AS source
-		ON target.[URL] = source.URL
-		WHEN MATCHED THEN
-			UPDATE SET
-				[Title] = @Title,
-				[Text] = @Text,
-				[ThumbnailURL] = @ThumbnailURL,
-				[DT] = @DT,
-				[CheckSum] = @CheckSum,
-				[SequenceNum] = @SequenceNum
-		WHEN NOT MATCHED THEN
-			INSERT ([Language_UID], [SequenceNum], [DT], [Title], [Text], [URL], [ThumbnailURL], [CheckSum])
-			VALUES (@LanguageUID, @SequenceNum, @DT, @Title, @Text, @URL, @ThumbnailURL, @CheckSum);
+		IF NOT EXISTS (SELECT 1 FROM TblRefLanguages WHERE [Alias] = @Alias)
+			INSERT INTO TblRefLanguages ([Alias]) VALUES (@Alias)
 	`
 
-	// Получаем Language_UID по коду языка
-	languageUID, err := r.getLanguageUID(ctx, card.Language)
+	for _, alias := range languages {
+		if _, err := r.db.ExecContext(ctx, query, sql.Named("Alias", alias)); err != nil {
+			return fmt.Errorf("failed to register language %s: %w", alias, err)
+		}
+	}
+
+	return nil
+}
+
+// UpsertCard сохраняет или обновляет карточку.
+// MERGE обновляет строку только при изменении CheckSum; OUTPUT $action различает вставку и обновление,
+// а отсутствие строки в OUTPUT означает, что карточка не изменилась.
+// При изменении Title, Text или ThumbnailURL предыдущая версия сохраняется в TblNewsRevisions;
+// обновление только CheckSum/SequenceNum (сдвиг в листинге) возвращает UpsertUnchanged.
+func (r *Repository) UpsertCard(ctx context.Context, card *storage.ArticleCard) (storage.UpsertResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	// Один MERGE атомарен и без транзакции
+	results, err := r.mergeCards(ctx, r.db, []*storage.ArticleCard{card})
 	if err != nil {
-		r.logger.Error("Failed to get language UID",
-			"language", card.Language,
-			"error", err.Error(),
-		)
-		return false, false, err
+		return storage.UpsertUnchanged, err
 	}
+	return results[0], nil
+}
 
-	stmt, err := r.db.PrepareContext(ctx, query)
+// UpsertCards сохраняет карточки в одной транзакции: либо все, либо ни одной.
+// Пакет пишется многострочными MERGE (см. mergeBatches), а не MERGE на каждую карточку
+func (r *Repository) UpsertCards(ctx context.Context, cards []*storage.ArticleCard) ([]storage.UpsertResult, error) {
+	if len(cards) == 0 {
+		return nil, nil
+	}
+
+	// Таймаут команды — на каждый MERGE пакета
+	batches := mergeBatches(cards, maxMergeCards)
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout*time.Duration(len(batches)))
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return false, false, fmt.Errorf("failed to prepare statement: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer func() {
-		if err := stmt.Close(); err != nil {
-			r.logger.Error("Failed to close statement", "error", err.Error())
-		}
+		// После Commit откат вернёт sql.ErrTxDone — это ожидаемо
+		_ = tx.Rollback()
 	}()
 
-	result, err := stmt.ExecContext(ctx,
-		sql.Named("LanguageUID", languageUID),
-		sql.Named("SequenceNum", card.SequenceNum),
-		sql.Named("Title", card.Title),
-		sql.Named("Text", card.Text),
-		sql.Named("URL", card.CanonicalURL),
-		sql.Named("ThumbnailURL", card.ImageURL),
-		sql.Named("DT", card.Date),
-		sql.Named("CheckSum", card.CheckSum),
-	)
+	results := make([]storage.UpsertResult, 0, len(cards))
+	for _, batch := range batches {
+		batchResults, err := r.mergeCards(ctx, tx, batch)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, batchResults...)
+	}
 
-	/*r.logger.Debug("Executing upsert with parameters",
-		"url_len", len(card.CanonicalURL),
-		"title_len", len(card.Title),
-		"text_len", len(card.Text),
-		"image_url_len", len(card.ImageURL),
-		"checksum_len", len(card.CheckSum),
-	)*/
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-	if err != nil {
-		return false, false, fmt.Errorf("failed to execute upsert: %w", err)
+	return results, nil
+}
+
+// mergeBatches делит карточки на пакеты не больше size для одного MERGE.
+// Повтор URL начинает новый пакет: MERGE не может изменить одну строку дважды,
+// а повтор должен примениться поверх предыдущей версии, как при поочерёдном сохранении
+func mergeBatches(cards []*storage.ArticleCard, size int) [][]*storage.ArticleCard {
+	var batches [][]*storage.ArticleCard
+	var batch []*storage.ArticleCard
+	urls := make(map[string]bool)
+
+	for _, card := range cards {
+		if len(batch) == size || urls[card.CanonicalURL] {
+			batches = append(batches, batch)
+			batch = nil
+			urls = make(map[string]bool)
+		}
+		batch = append(batch, card)
+		urls[card.CanonicalURL] = true
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return append(batches, batch)
+}
+
+// queryer — *sql.DB или *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// mergeCards сохраняет карточки одним upsertCardsQuery; результаты — в порядке карточек
+func (r *Repository) mergeCards(ctx context.Context, q queryer, cards []*storage.ArticleCard) ([]storage.UpsertResult, error) {
+	args := make([]any, 0, len(cards)*12)
+	for i, card := range cards {
+		// Получаем Language_UID по коду языка
+		languageUID, err := r.getLanguageUID(ctx, card.Language)
+		if err != nil {
+			r.logger.Error("Failed to get language UID",
+				"language", card.Language,
+				"error", err.Error(),
+			)
+			return nil, err
+		}
+		args = append(args, upsertArgs(i, languageUID, card)...)
+	}
+
+	rows, err := q.QueryContext(ctx, upsertCardsQuery(len(cards)), args...)
 	if err != nil {
-		return false, false, fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, fmt.Errorf("failed to execute upsert: %w", err)
 	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Error("Failed to close rows", "error", err.Error())
+		}
+	}()
 
-	// Если вставлена новая строка
-	if rowsAffected > 0 {
-		isNew = true
-	} else {
-		// Если обновлена существующая
-		isUpdated = true
+	// Карточки без строки в OUTPUT совпали по CheckSum — не изменились
+	results := make([]storage.UpsertResult, len(cards))
+	for rows.Next() {
+		var (
+			idx     int
+			action  string
+			changed bool
+		)
+		if err := rows.Scan(&idx, &action, &changed); err != nil {
+			return nil, fmt.Errorf("failed to scan upsert result: %w", err)
+		}
+		if idx < 0 || idx >= len(cards) {
+			return nil, fmt.Errorf("unexpected upsert result index: %d", idx)
+		}
+
+		switch {
+		case action == "INSERT":
+			results[idx] = storage.UpsertInserted
+		case changed:
+			results[idx] = storage.UpsertUpdated
+		default:
+			// Обновились только CheckSum и SequenceNum
+			results[idx] = storage.UpsertUnchanged
+		}
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read upsert results: %w", err)
+	}
+
+	return results, nil
+}
 
-	return isNew, isUpdated, nil
+// upsertArgs — параметры карточки i для upsertCardsQuery
+func upsertArgs(i int, languageUID int, card *storage.ArticleCard) []any {
+	suffix := strconv.Itoa(i)
+	return []any{
+		sql.Named("LanguageUID"+suffix, languageUID),
+		sql.Named("SequenceNum"+suffix, card.SequenceNum),
+		sql.Named("Title"+suffix, card.Title),
+		sql.Named("Text"+suffix, card.Text),
+		sql.Named("URL"+suffix, card.CanonicalURL),
+		sql.Named("ThumbnailURL"+suffix, card.ImageURL),
+		sql.Named("DT"+suffix, card.Date),
+		sql.Named("CheckSum"+suffix, card.CheckSum),
+		sql.Named("Body"+suffix, card.Body),
+		sql.Named("OgImageURL"+suffix, card.OgImageURL),
+		sql.Named("DetailDT"+suffix, sql.NullTime{Time: card.DetailDate, Valid: !card.DetailDate.IsZero()}),
+		sql.Named("ThumbnailData"+suffix, card.ImageData),
+	}
 }
 
 // ExistsByURL проверяет наличие карточки по URL
@@ -162,6 +346,24 @@ func (r *Repository) ExistsByURL(ctx context.Context, url string) (bool, error)
 	return count > 0, nil
 }
 
+// GetUpdatedAt возвращает время последней записи карточки по URL.
+// UpdatedDT пуст у строк, записанных до миграции 0002_detail_columns, — время неизвестно
+func (r *Repository) GetUpdatedAt(ctx context.Context, url string) (time.Time, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	var updatedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `SELECT [UpdatedDT] FROM TblNews WHERE [URL] = @URL`, sql.Named("URL", url)).Scan(&updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	return updatedAt.Time, true, nil
+}
+
 // GetLatestKnownDate получает последнюю загруженную дату для языка
 func (r *Repository) GetLatestKnownDate(ctx context.Context, lang string) (time.Time, error) {
 	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
@@ -231,6 +433,13 @@ func (r *Repository) GetCardCount(ctx context.Context, lang string) (int, error)
 
 // getLanguageUID получает UID языка по коду (ru, ky)
 func (r *Repository) getLanguageUID(ctx context.Context, langAlias string) (int, error) {
+	r.langMu.RLock()
+	uid, ok := r.langUIDs[langAlias]
+	r.langMu.RUnlock()
+	if ok {
+		return uid, nil
+	}
+
 	query := `SELECT UID FROM TblRefLanguages WHERE Alias = @Alias`
 
 	stmt, err := r.db.PrepareContext(ctx, query)
@@ -243,7 +452,6 @@ func (r *Repository) getLanguageUID(ctx context.Context, langAlias string) (int,
 		}
 	}()
 
-	var uid int
 	err = stmt.QueryRowContext(ctx, sql.Named("Alias", langAlias)).Scan(&uid)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -252,9 +460,93 @@ func (r *Repository) getLanguageUID(ctx context.Context, langAlias string) (int,
 		return 0, fmt.Errorf("failed to query database: %w", err)
 	}
 
+	r.langMu.Lock()
+	r.langUIDs[langAlias] = uid
+	r.langMu.Unlock()
+
 	return uid, nil
 }
 
+// ListRevisions возвращает предыдущие версии карточки по URL (новые первыми)
+func (r *Repository) ListRevisions(ctx context.Context, url string) ([]storage.Revision, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	query := `
+		SELECT [URL], ISNULL([Title], N''), ISNULL([Text], N''), ISNULL([ThumbnailURL], N''), ISNULL([CheckSum], N''), [ObservedDT]
+		FROM TblNewsRevisions
+		WHERE [URL] = @URL
+		ORDER BY [ObservedDT] DESC, [UID] DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sql.Named("URL", url))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var revisions []storage.Revision
+	for rows.Next() {
+		var revision storage.Revision
+		if err := rows.Scan(&revision.URL, &revision.Title, &revision.Text, &revision.ImageURL, &revision.CheckSum, &revision.ObservedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// RecordRun сохраняет запись о проходе в историю запусков (TblParserRuns, миграция 0004_parser_runs)
+func (r *Repository) RecordRun(ctx context.Context, run *storage.RunRecord) error {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	languageUID, err := r.getLanguageUID(ctx, run.Language)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO TblParserRuns ([Language_UID], [StartedDT], [FinishedDT], [TotalPages], [TotalCards], [OldCards], [CachedPages], [NewCards], [UpdatedCards], [UnchangedCards], [StoppedReason], [ErrorText])
+		VALUES (@LanguageUID, @StartedDT, @FinishedDT, @TotalPages, @TotalCards, @OldCards, @CachedPages, @NewCards, @UpdatedCards, @UnchangedCards, NULLIF(@StoppedReason, N''), NULLIF(@ErrorText, N''))
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		sql.Named("LanguageUID", languageUID),
+		sql.Named("StartedDT", run.StartedAt),
+		sql.Named("FinishedDT", run.FinishedAt),
+		sql.Named("TotalPages", run.TotalPages),
+		sql.Named("TotalCards", run.TotalCards),
+		sql.Named("OldCards", run.OldCards),
+		sql.Named("CachedPages", run.CachedPages),
+		sql.Named("NewCards", run.NewCards),
+		sql.Named("UpdatedCards", run.UpdatedCards),
+		sql.Named("UnchangedCards", run.UnchangedCards),
+		sql.Named("StoppedReason", run.StoppedReason),
+		sql.Named("ErrorText", run.Error),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert run record: %w", err)
+	}
+
+	return nil
+}
+
+// Ping проверяет соединение с БД
+func (r *Repository) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
 // Close закрывает соединение с БД
 func (r *Repository) Close() error {
 	if r.db != nil {
@@ -267,7 +559,11 @@ func (r *Repository) UpdateNewsCheckSum(ctx context.Context) (string, error) {
 	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
 	defer cancel()
 
-	query := `EXEC USP_UpdateNewsCheckSum @result OUTPUT, @Msg OUTPUT`
+	query := `
+		DECLARE @result INT, @Msg NVARCHAR(4000);
+		EXEC USP_UpdateNewsCheckSum @result OUTPUT, @Msg OUTPUT;
+		SELECT @result, @Msg;
+	`
 
 	var result int
 	var msg string
@@ -0,0 +1,114 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"oshcity-news-parser/internal/observability"
+	"oshcity-news-parser/internal/storage"
+	"oshcity-news-parser/internal/storage/storagetest"
+)
+
+// testDSNEnv — DSN отдельной тестовой БД; таблицы новостей в ней очищаются перед каждой проверкой
+const testDSNEnv = "OSHCITY_TEST_MSSQL_DSN"
+
+func TestContract(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	storagetest.Run(t, func(t *testing.T) storage.Repository {
+		repo, err := NewRepository(dsn, 15000, 4, 4, 60, 60, observability.NewLogger("", "error", 0, 0, 0))
+		if err != nil {
+			t.Fatalf("NewRepository error: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.Close() })
+
+		ctx := context.Background()
+		runner, err := repo.Migrator()
+		if err != nil {
+			t.Fatalf("Migrator error: %v", err)
+		}
+		if _, err := runner.Up(ctx); err != nil {
+			t.Fatalf("Up error: %v", err)
+		}
+		if err := repo.RegisterLanguages(ctx, []string{"ru", "kg"}); err != nil {
+			t.Fatalf("RegisterLanguages error: %v", err)
+		}
+		if _, err := repo.db.ExecContext(ctx, `
+			DELETE FROM dbo.TblNewsRevisions;
+			DELETE FROM dbo.TblParserRuns;
+			DELETE FROM dbo.TblNewsCheckSums;
+			DELETE FROM dbo.TblNews;
+		`); err != nil {
+			t.Fatalf("failed to reset tables: %v", err)
+		}
+
+		return repo
+	})
+}
+
+func TestMergeBatches(t *testing.T) {
+	card := func(n int) *storage.ArticleCard {
+		return &storage.ArticleCard{CanonicalURL: fmt.Sprintf("https://oshcity.gov.kg/ru/novosti/%d/", n)}
+	}
+
+	tests := []struct {
+		name  string
+		urls  []int
+		size  int
+		sizes []int
+	}{
+		{name: "single batch", urls: []int{1, 2, 3}, size: 150, sizes: []int{3}},
+		{name: "size limit", urls: []int{1, 2, 3, 4, 5}, size: 2, sizes: []int{2, 2, 1}},
+		{name: "repeated url", urls: []int{1, 2, 1, 3}, size: 150, sizes: []int{2, 2}},
+	}
+
+	for _, tt := range tests {
+		cards := make([]*storage.ArticleCard, 0, len(tt.urls))
+		for _, n := range tt.urls {
+			cards = append(cards, card(n))
+		}
+
+		batches := mergeBatches(cards, tt.size)
+		sizes := make([]int, 0, len(batches))
+		var merged []*storage.ArticleCard
+		for _, batch := range batches {
+			sizes = append(sizes, len(batch))
+			merged = append(merged, batch...)
+		}
+		if fmt.Sprint(sizes) != fmt.Sprint(tt.sizes) {
+			t.Errorf("%s: batch sizes = %v, want %v", tt.name, sizes, tt.sizes)
+		}
+		// Порядок карточек сохраняется
+		for i := range cards {
+			if merged[i] != cards[i] {
+				t.Errorf("%s: card %d out of order", tt.name, i)
+			}
+		}
+	}
+}
+
+func TestUpsertCardsQueryParameters(t *testing.T) {
+	// Все параметры карточек запроса передаются upsertArgs, и их не больше лимита SQL Server
+	query := upsertCardsQuery(maxMergeCards)
+	args := make([]any, 0, maxMergeCards*12)
+	for i := 0; i < maxMergeCards; i++ {
+		args = append(args, upsertArgs(i, 1, &storage.ArticleCard{})...)
+	}
+
+	if len(args) > 2100 {
+		t.Errorf("parameters = %d, want <= 2100", len(args))
+	}
+	for _, arg := range args {
+		name := "@" + arg.(sql.NamedArg).Name
+		if !strings.Contains(query, name+",") && !strings.Contains(query, name+" ") && !strings.Contains(query, name+")") {
+			t.Errorf("parameter %s is not used in query", name)
+		}
+	}
+}
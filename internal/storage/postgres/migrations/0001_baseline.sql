@@ -0,0 +1,30 @@
+-- Базовая схема: эквиваленты TblRefLanguages / TblNews из MS SQL.
+-- IF NOT EXISTS — миграция безопасно применяется к БД, созданной до появления миграций
+
+CREATE TABLE IF NOT EXISTS tbl_ref_languages (
+	uid   SERIAL PRIMARY KEY,
+	alias VARCHAR(10) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS tbl_news (
+	uid           BIGSERIAL PRIMARY KEY,
+	language_uid  INT NOT NULL REFERENCES tbl_ref_languages (uid),
+	sequence_num  INT NOT NULL DEFAULT 0,
+	dt            TIMESTAMP NOT NULL,
+	title         TEXT NOT NULL,
+	text          TEXT NOT NULL,
+	url           VARCHAR(2048) NOT NULL UNIQUE,
+	thumbnail_url VARCHAR(2048) NOT NULL DEFAULT '',
+	check_sum     VARCHAR(320) NOT NULL,
+	created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_tbl_news_language_dt ON tbl_news (language_uid, dt DESC);
+
+CREATE TABLE IF NOT EXISTS tbl_news_checksums (
+	language_uid INT PRIMARY KEY REFERENCES tbl_ref_languages (uid),
+	news_count   INT NOT NULL,
+	check_sum    CHAR(64) NOT NULL,
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
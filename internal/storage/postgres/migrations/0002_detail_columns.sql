@@ -0,0 +1,6 @@
+-- Поля детальной страницы и байты миниатюры
+
+ALTER TABLE tbl_news ADD COLUMN IF NOT EXISTS body TEXT;
+ALTER TABLE tbl_news ADD COLUMN IF NOT EXISTS og_image_url VARCHAR(2048);
+ALTER TABLE tbl_news ADD COLUMN IF NOT EXISTS detail_dt TIMESTAMP;
+ALTER TABLE tbl_news ADD COLUMN IF NOT EXISTS thumbnail_data BYTEA;
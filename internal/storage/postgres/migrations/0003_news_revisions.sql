@@ -0,0 +1,13 @@
+-- Предыдущие версии карточек при изменении check_sum
+
+CREATE TABLE IF NOT EXISTS tbl_news_revisions (
+	uid           BIGSERIAL PRIMARY KEY,
+	url           VARCHAR(2048) NOT NULL,
+	title         TEXT NOT NULL,
+	text          TEXT NOT NULL,
+	thumbnail_url VARCHAR(2048) NOT NULL DEFAULT '',
+	check_sum     VARCHAR(320) NOT NULL,
+	observed_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_tbl_news_revisions_url ON tbl_news_revisions (url, observed_at DESC);
@@ -0,0 +1,19 @@
+-- История запусков парсера
+
+CREATE TABLE IF NOT EXISTS tbl_parser_runs (
+	uid             BIGSERIAL PRIMARY KEY,
+	language_uid    INT NOT NULL REFERENCES tbl_ref_languages (uid),
+	started_at      TIMESTAMPTZ NOT NULL,
+	finished_at     TIMESTAMPTZ NOT NULL,
+	total_pages     INT NOT NULL,
+	total_cards     INT NOT NULL,
+	old_cards       INT NOT NULL,
+	cached_pages    INT NOT NULL,
+	new_cards       INT NOT NULL,
+	updated_cards   INT NOT NULL,
+	unchanged_cards INT NOT NULL DEFAULT 0,
+	stopped_reason  TEXT,
+	error_text      TEXT
+);
+
+CREATE INDEX IF NOT EXISTS ix_tbl_parser_runs_language_started ON tbl_parser_runs (language_uid, started_at DESC);
@@ -1 +1,462 @@
 package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"oshcity-news-parser/internal/observability"
+	"oshcity-news-parser/internal/storage"
+	"oshcity-news-parser/internal/storage/migrate"
+)
+
+// migrationsFS — версионированные миграции схемы (см. migrate.Runner)
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// upsertCardQuery — upsert карточки в tbl_news.
+// xmax = 0 только у строки, вставленной в текущей транзакции.
+// Строка обновляется только при изменении check_sum, иначе RETURNING не возвращает строк.
+// Поля детальной страницы и text не затираются, если в карточке они пустые.
+// CTE previous видит строку до обновления — её версия сохраняется в tbl_news_revisions,
+// если изменились title, text или thumbnail_url (check_sum меняется и от позиции в листинге)
+const upsertCardQuery = `
+	WITH previous AS (
+		SELECT title, text, thumbnail_url, check_sum,
+			(title IS DISTINCT FROM $4 OR ($5 <> '' AND text IS DISTINCT FROM $5) OR thumbnail_url IS DISTINCT FROM $7) AS changed
+		FROM tbl_news WHERE url = $6
+	), upsert AS (
+		INSERT INTO tbl_news (language_uid, sequence_num, dt, title, text, url, thumbnail_url, check_sum, body, og_image_url, detail_dt, thumbnail_data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, $12)
+		ON CONFLICT (url) DO UPDATE SET
+			title = EXCLUDED.title,
+			text = COALESCE(NULLIF(EXCLUDED.text, ''), tbl_news.text),
+			thumbnail_url = EXCLUDED.thumbnail_url,
+			dt = EXCLUDED.dt,
+			check_sum = EXCLUDED.check_sum,
+			sequence_num = EXCLUDED.sequence_num,
+			body = COALESCE(EXCLUDED.body, tbl_news.body),
+			og_image_url = COALESCE(EXCLUDED.og_image_url, tbl_news.og_image_url),
+			detail_dt = COALESCE(EXCLUDED.detail_dt, tbl_news.detail_dt),
+			thumbnail_data = COALESCE(EXCLUDED.thumbnail_data, tbl_news.thumbnail_data),
+			updated_at = now()
+		WHERE tbl_news.check_sum IS DISTINCT FROM EXCLUDED.check_sum
+		RETURNING (xmax = 0) AS inserted
+	), revision AS (
+		INSERT INTO tbl_news_revisions (url, title, text, thumbnail_url, check_sum, observed_at)
+		SELECT $6, p.title, p.text, p.thumbnail_url, p.check_sum, now()
+		FROM previous p, upsert u
+		WHERE NOT u.inserted AND p.changed
+	)
+	SELECT u.inserted, COALESCE((SELECT changed FROM previous), false) FROM upsert u
+`
+
+type Repository struct {
+	db             *sql.DB
+	commandTimeout time.Duration
+	logger         *observability.Logger
+
+	// Кэш uid языка по alias — справочник языков не меняется во время работы
+	langMu   sync.RWMutex
+	langUIDs map[string]int
+}
+
+func NewRepository(
+	dsn string,
+	commandTimeoutMS int,
+	maxOpenConnections int,
+	maxIdleConnections int,
+	connectionMaxLifetime int,
+	connectionMaxIdleTime int,
+	logger *observability.Logger,
+) (*Repository, error) {
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db.SetMaxOpenConns(maxOpenConnections)
+	db.SetMaxIdleConns(maxIdleConnections)
+	db.SetConnMaxLifetime(time.Duration(connectionMaxLifetime) * time.Second)
+	db.SetConnMaxIdleTime(time.Duration(connectionMaxIdleTime) * time.Second)
+
+	// Тестируем соединение
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &Repository{
+		db:             db,
+		commandTimeout: time.Duration(commandTimeoutMS) * time.Millisecond,
+		logger:         logger,
+		langUIDs:       make(map[string]int),
+	}, nil
+}
+
+// Migrator возвращает runner встроенных миграций схемы
+func (r *Repository) Migrator() (*migrate.Runner, error) {
+	return migrate.NewRunner(r.db, migrate.Postgres, migrationsFS, "migrations", r.logger)
+}
+
+// RegisterLanguages добавляет языки из конфига в tbl_ref_languages
+func (r *Repository) RegisterLanguages(ctx context.Context, languages []string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	for _, alias := range languages {
+		_, err := r.db.ExecContext(ctx,
+			`INSERT INTO tbl_ref_languages (alias) VALUES ($1) ON CONFLICT (alias) DO NOTHING`,
+			alias,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to register language %s: %w", alias, err)
+		}
+	}
+
+	return nil
+}
+
+// UpsertCard сохраняет или обновляет карточку
+func (r *Repository) UpsertCard(ctx context.Context, card *storage.ArticleCard) (storage.UpsertResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	// Получаем language_uid по коду языка
+	languageUID, err := r.getLanguageUID(ctx, card.Language)
+	if err != nil {
+		r.logger.Error("Failed to get language UID",
+			"language", card.Language,
+			"error", err.Error(),
+		)
+		return storage.UpsertUnchanged, err
+	}
+
+	stmt, err := r.db.PrepareContext(ctx, upsertCardQuery)
+	if err != nil {
+		return storage.UpsertUnchanged, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer func() {
+		if err := stmt.Close(); err != nil {
+			r.logger.Error("Failed to close statement", "error", err.Error())
+		}
+	}()
+
+	return execUpsert(ctx, stmt, languageUID, card)
+}
+
+// UpsertCards сохраняет карточки в одной транзакции: либо все, либо ни одной
+func (r *Repository) UpsertCards(ctx context.Context, cards []*storage.ArticleCard) ([]storage.UpsertResult, error) {
+	if len(cards) == 0 {
+		return nil, nil
+	}
+
+	// Таймаут команды — на каждую карточку пакета
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout*time.Duration(len(cards)))
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		// После Commit откат вернёт sql.ErrTxDone — это ожидаемо
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, upsertCardQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer func() {
+		if err := stmt.Close(); err != nil {
+			r.logger.Error("Failed to close statement", "error", err.Error())
+		}
+	}()
+
+	results := make([]storage.UpsertResult, 0, len(cards))
+	for _, card := range cards {
+		languageUID, err := r.getLanguageUID(ctx, card.Language)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := execUpsert(ctx, stmt, languageUID, card)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upsert %s: %w", card.CanonicalURL, err)
+		}
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return results, nil
+}
+
+// execUpsert выполняет подготовленный upsertCardQuery для одной карточки
+func execUpsert(ctx context.Context, stmt *sql.Stmt, languageUID int, card *storage.ArticleCard) (storage.UpsertResult, error) {
+	var inserted, changed bool
+	err := stmt.QueryRowContext(ctx,
+		languageUID,
+		card.SequenceNum,
+		card.Date,
+		card.Title,
+		card.Text,
+		card.CanonicalURL,
+		card.ImageURL,
+		card.CheckSum,
+		card.Body,
+		card.OgImageURL,
+		sql.NullTime{Time: card.DetailDate, Valid: !card.DetailDate.IsZero()},
+		card.ImageData,
+	).Scan(&inserted, &changed)
+	if err != nil {
+		// Конфликт по url, но check_sum не изменился — строка не обновлялась
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.UpsertUnchanged, nil
+		}
+		return storage.UpsertUnchanged, fmt.Errorf("failed to execute upsert: %w", err)
+	}
+
+	switch {
+	case inserted:
+		return storage.UpsertInserted, nil
+	case changed:
+		return storage.UpsertUpdated, nil
+	default:
+		// Обновились только check_sum и sequence_num
+		return storage.UpsertUnchanged, nil
+	}
+}
+
+// ExistsByURL проверяет наличие карточки по URL
+func (r *Repository) ExistsByURL(ctx context.Context, url string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tbl_news WHERE url = $1)`, url).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetUpdatedAt возвращает время последней записи карточки по URL
+func (r *Repository) GetUpdatedAt(ctx context.Context, url string) (time.Time, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	var updatedAt time.Time
+	err := r.db.QueryRowContext(ctx, `SELECT updated_at FROM tbl_news WHERE url = $1`, url).Scan(&updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	return updatedAt.UTC(), true, nil
+}
+
+// GetLatestKnownDate получает последнюю загруженную дату для языка
+func (r *Repository) GetLatestKnownDate(ctx context.Context, lang string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	languageUID, err := r.getLanguageUID(ctx, lang)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latestDate sql.NullTime
+	err = r.db.QueryRowContext(ctx, `SELECT MAX(dt) FROM tbl_news WHERE language_uid = $1`, languageUID).Scan(&latestDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	if !latestDate.Valid {
+		// Если нет данных, возвращаем время 1 года назад
+		return time.Now().UTC().AddDate(-1, 0, 0), nil
+	}
+
+	return latestDate.Time, nil
+}
+
+// GetCardCount получает количество загруженных карточек для языка
+func (r *Repository) GetCardCount(ctx context.Context, lang string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	languageUID, err := r.getLanguageUID(ctx, lang)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tbl_news WHERE language_uid = $1`, languageUID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	return count, nil
+}
+
+// UpdateNewsCheckSum — аналог USP_UpdateNewsCheckSum: пересчитывает
+// агрегированную контрольную сумму новостей по каждому языку
+func (r *Repository) UpdateNewsCheckSum(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO tbl_news_checksums (language_uid, news_count, check_sum, updated_at)
+		SELECT
+			language_uid,
+			COUNT(*),
+			encode(sha256(convert_to(string_agg(check_sum, '' ORDER BY dt, url), 'UTF8')), 'hex'),
+			now()
+		FROM tbl_news
+		GROUP BY language_uid
+		ON CONFLICT (language_uid) DO UPDATE SET
+			news_count = EXCLUDED.news_count,
+			check_sum = EXCLUDED.check_sum,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute UpdateNewsCheckSum: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return "", fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return fmt.Sprintf("checksums updated for %d languages", rowsAffected), nil
+}
+
+// getLanguageUID получает UID языка по коду (ru, ky)
+func (r *Repository) getLanguageUID(ctx context.Context, langAlias string) (int, error) {
+	r.langMu.RLock()
+	uid, ok := r.langUIDs[langAlias]
+	r.langMu.RUnlock()
+	if ok {
+		return uid, nil
+	}
+
+	err := r.db.QueryRowContext(ctx, `SELECT uid FROM tbl_ref_languages WHERE alias = $1`, langAlias).Scan(&uid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("language not found: %s", langAlias)
+		}
+		return 0, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	r.langMu.Lock()
+	r.langUIDs[langAlias] = uid
+	r.langMu.Unlock()
+
+	return uid, nil
+}
+
+// ListRevisions возвращает предыдущие версии карточки по URL (новые первыми)
+func (r *Repository) ListRevisions(ctx context.Context, url string) ([]storage.Revision, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	query := `
+		SELECT url, title, text, thumbnail_url, check_sum, observed_at
+		FROM tbl_news_revisions
+		WHERE url = $1
+		ORDER BY observed_at DESC, uid DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var revisions []storage.Revision
+	for rows.Next() {
+		var revision storage.Revision
+		if err := rows.Scan(&revision.URL, &revision.Title, &revision.Text, &revision.ImageURL, &revision.CheckSum, &revision.ObservedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// RecordRun сохраняет запись о проходе в историю запусков (tbl_parser_runs)
+func (r *Repository) RecordRun(ctx context.Context, run *storage.RunRecord) error {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	languageUID, err := r.getLanguageUID(ctx, run.Language)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO tbl_parser_runs (language_uid, started_at, finished_at, total_pages, total_cards, old_cards, cached_pages, new_cards, updated_cards, unchanged_cards, stopped_reason, error_text)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), NULLIF($12, ''))
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		languageUID,
+		run.StartedAt,
+		run.FinishedAt,
+		run.TotalPages,
+		run.TotalCards,
+		run.OldCards,
+		run.CachedPages,
+		run.NewCards,
+		run.UpdatedCards,
+		run.UnchangedCards,
+		run.StoppedReason,
+		run.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert run record: %w", err)
+	}
+
+	return nil
+}
+
+// Ping проверяет соединение с БД
+func (r *Repository) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с БД
+func (r *Repository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
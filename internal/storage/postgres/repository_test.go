@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"oshcity-news-parser/internal/observability"
+	"oshcity-news-parser/internal/storage"
+	"oshcity-news-parser/internal/storage/storagetest"
+)
+
+// testDSNEnv — DSN отдельной тестовой БД; таблицы новостей в ней очищаются перед каждой проверкой
+const testDSNEnv = "OSHCITY_TEST_POSTGRES_DSN"
+
+func TestContract(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	storagetest.Run(t, func(t *testing.T) storage.Repository {
+		repo, err := NewRepository(dsn, 15000, 4, 4, 60, 60, observability.NewLogger("", "error", 0, 0, 0))
+		if err != nil {
+			t.Fatalf("NewRepository error: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.Close() })
+
+		ctx := context.Background()
+		runner, err := repo.Migrator()
+		if err != nil {
+			t.Fatalf("Migrator error: %v", err)
+		}
+		if _, err := runner.Up(ctx); err != nil {
+			t.Fatalf("Up error: %v", err)
+		}
+		if err := repo.RegisterLanguages(ctx, []string{"ru", "kg"}); err != nil {
+			t.Fatalf("RegisterLanguages error: %v", err)
+		}
+		if _, err := repo.db.ExecContext(ctx, `TRUNCATE tbl_news, tbl_news_revisions, tbl_news_checksums, tbl_parser_runs`); err != nil {
+			t.Fatalf("failed to reset tables: %v", err)
+		}
+
+		return repo
+	})
+}
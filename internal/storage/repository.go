@@ -9,22 +9,40 @@ import (
 type ArticleCard struct {
 	CanonicalURL string // URL из Card
 	Title        string
-	Text         string
+	Text         string // Пусто при обновлении — оставить сохранённый (превью с детальной страницы)
 	ImageURL     string // ThumbnailURL из Card
 	Date         time.Time
 	Language     string
 	SequenceNum  int    // Из Card
 	CheckSum     string // SHA256 контента (256 символов)
+
+	// Поля детальной страницы (заполняются, если включён detail.enabled)
+	Body       string    // Полный текст статьи
+	OgImageURL string    // og:image детальной страницы
+	DetailDate time.Time // Дата публикации с детальной страницы (zero — неизвестна)
+
+	ImageData []byte // Байты миниатюры (заполняются, если включён images.store_in_db)
 }
 
 // Repository интерфейс для работы с хранилищем карточек
 type Repository interface {
-	// UpsertCard сохраняет или обновляет карточку, возвращает (isNew, isUpdated, error)
-	UpsertCard(ctx context.Context, card *ArticleCard) (isNew bool, isUpdated bool, err error)
+	// UpsertCard сохраняет или обновляет карточку; строка с тем же CheckSum не перезаписывается.
+	// Ревизия пишется, только если изменились Title, Text или ImageURL, а не при любой смене CheckSum:
+	// CheckSum меняется и от сдвига карточки в листинге, и от новых байтов миниатюры по тому же URL —
+	// такие изменения обновляют строку без ревизии (см. storagetest: SequenceNumOnlyChange, ThumbnailBytesOnlyChange)
+	UpsertCard(ctx context.Context, card *ArticleCard) (UpsertResult, error)
+
+	// UpsertCards сохраняет пакет карточек в одной транзакции (всё или ничего);
+	// результаты возвращаются в порядке карточек
+	UpsertCards(ctx context.Context, cards []*ArticleCard) ([]UpsertResult, error)
 
 	// ExistsByURL проверяет наличие карточки по URL
 	ExistsByURL(ctx context.Context, url string) (bool, error)
 
+	// GetUpdatedAt возвращает время последней записи карточки по URL (вставки или изменения CheckSum);
+	// found = false — карточки нет, нулевое время — неизвестно (строка записана до появления колонки)
+	GetUpdatedAt(ctx context.Context, url string) (updatedAt time.Time, found bool, err error)
+
 	// GetLatestKnownDate получает последнюю загруженную дату для языка
 	GetLatestKnownDate(ctx context.Context, lang string) (time.Time, error)
 
@@ -32,4 +50,60 @@ type Repository interface {
 	GetCardCount(ctx context.Context, lang string) (int, error)
 
 	UpdateNewsCheckSum(ctx context.Context) (string, error)
+
+	// ListRevisions возвращает предыдущие версии карточки по URL (новые первыми)
+	ListRevisions(ctx context.Context, url string) ([]Revision, error)
+
+	// RecordRun сохраняет запись о проходе по языку в историю запусков
+	RecordRun(ctx context.Context, run *RunRecord) error
+
+	// Ping проверяет соединение с БД (для /readyz)
+	Ping(ctx context.Context) error
+}
+
+// UpsertResult — результат UpsertCard
+type UpsertResult int
+
+const (
+	UpsertUnchanged UpsertResult = iota // Заголовок, текст и миниатюра не изменились (CheckSum и SequenceNum могут обновиться)
+	UpsertInserted                      // Новая карточка
+	UpsertUpdated                       // Изменились Title, Text или ImageURL — предыдущая версия сохранена в ревизиях
+)
+
+func (r UpsertResult) String() string {
+	switch r {
+	case UpsertInserted:
+		return "new"
+	case UpsertUpdated:
+		return "updated"
+	default:
+		return "unchanged"
+	}
+}
+
+// Revision — предыдущая версия карточки, сохраняемая при изменении Title, Text или ImageURL.
+// CheckSum включает позицию в листинге и байты миниатюры, поэтому сам по себе ревизию не создаёт
+type Revision struct {
+	URL        string
+	Title      string
+	Text       string
+	ImageURL   string
+	CheckSum   string
+	ObservedAt time.Time // Когда версия была заменена новой (последний раз наблюдалась на сайте)
+}
+
+// RunRecord — запись истории запусков: один проход пагинации по языку
+type RunRecord struct {
+	Language       string
+	StartedAt      time.Time
+	FinishedAt     time.Time
+	TotalPages     int
+	TotalCards     int
+	OldCards       int
+	CachedPages    int
+	NewCards       int
+	UpdatedCards   int
+	UnchangedCards int // Карточки с тем же CheckSum
+	StoppedReason  string
+	Error          string // Текст ошибки (пусто — проход завершился успешно)
 }
@@ -0,0 +1,30 @@
+-- Базовая схема: эквиваленты TblRefLanguages / TblNews из MS SQL.
+-- Даты хранятся текстом в UTC ("2006-01-02 15:04:05.000000"), чтобы MAX(dt) и сортировка работали как в других драйверах
+
+CREATE TABLE IF NOT EXISTS tbl_ref_languages (
+	uid   INTEGER PRIMARY KEY AUTOINCREMENT,
+	alias TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS tbl_news (
+	uid           INTEGER PRIMARY KEY AUTOINCREMENT,
+	language_uid  INTEGER NOT NULL REFERENCES tbl_ref_languages (uid),
+	sequence_num  INTEGER NOT NULL DEFAULT 0,
+	dt            TEXT NOT NULL,
+	title         TEXT NOT NULL,
+	text          TEXT NOT NULL,
+	url           TEXT NOT NULL UNIQUE,
+	thumbnail_url TEXT NOT NULL DEFAULT '',
+	check_sum     TEXT NOT NULL,
+	created_at    TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at    TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS ix_tbl_news_language_dt ON tbl_news (language_uid, dt DESC);
+
+CREATE TABLE IF NOT EXISTS tbl_news_checksums (
+	language_uid INTEGER PRIMARY KEY REFERENCES tbl_ref_languages (uid),
+	news_count   INTEGER NOT NULL,
+	check_sum    TEXT NOT NULL,
+	updated_at   TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,6 @@
+-- Поля детальной страницы и байты миниатюры
+
+ALTER TABLE tbl_news ADD COLUMN body TEXT;
+ALTER TABLE tbl_news ADD COLUMN og_image_url TEXT;
+ALTER TABLE tbl_news ADD COLUMN detail_dt TEXT;
+ALTER TABLE tbl_news ADD COLUMN thumbnail_data BLOB;
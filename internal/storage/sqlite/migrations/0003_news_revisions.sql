@@ -0,0 +1,13 @@
+-- Предыдущие версии карточек при изменении check_sum
+
+CREATE TABLE IF NOT EXISTS tbl_news_revisions (
+	uid           INTEGER PRIMARY KEY AUTOINCREMENT,
+	url           TEXT NOT NULL,
+	title         TEXT NOT NULL,
+	text          TEXT NOT NULL,
+	thumbnail_url TEXT NOT NULL DEFAULT '',
+	check_sum     TEXT NOT NULL,
+	observed_at   TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS ix_tbl_news_revisions_url ON tbl_news_revisions (url, observed_at DESC);
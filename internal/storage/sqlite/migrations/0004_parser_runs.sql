@@ -0,0 +1,19 @@
+-- История запусков парсера
+
+CREATE TABLE IF NOT EXISTS tbl_parser_runs (
+	uid             INTEGER PRIMARY KEY AUTOINCREMENT,
+	language_uid    INTEGER NOT NULL REFERENCES tbl_ref_languages (uid),
+	started_at      TEXT NOT NULL,
+	finished_at     TEXT NOT NULL,
+	total_pages     INTEGER NOT NULL,
+	total_cards     INTEGER NOT NULL,
+	old_cards       INTEGER NOT NULL,
+	cached_pages    INTEGER NOT NULL,
+	new_cards       INTEGER NOT NULL,
+	updated_cards   INTEGER NOT NULL,
+	unchanged_cards INTEGER NOT NULL DEFAULT 0,
+	stopped_reason  TEXT,
+	error_text      TEXT
+);
+
+CREATE INDEX IF NOT EXISTS ix_tbl_parser_runs_language_started ON tbl_parser_runs (language_uid, started_at DESC);
@@ -0,0 +1,530 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"oshcity-news-parser/internal/observability"
+	"oshcity-news-parser/internal/storage"
+	"oshcity-news-parser/internal/storage/migrate"
+)
+
+// migrationsFS — версионированные миграции схемы (см. migrate.Runner)
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// timeLayout — формат дат в текстовых колонках (UTC, микросекунды как в postgres);
+// фиксированная ширина — лексикографический порядок совпадает с хронологическим
+const timeLayout = "2006-01-02 15:04:05.000000"
+
+// Repository — хранилище на SQLite (pure Go, без cgo) для локальной разработки и тестов.
+// DSN — путь к файлу или ":memory:"
+type Repository struct {
+	db             *sql.DB
+	commandTimeout time.Duration
+	logger         *observability.Logger
+
+	// Кэш uid языка по alias — справочник языков не меняется во время работы
+	langMu   sync.RWMutex
+	langUIDs map[string]int
+}
+
+// NewRepository открывает БД SQLite. Настройки пула соединений других драйверов
+// здесь не нужны: SQLite допускает одного писателя, поэтому соединение одно
+func NewRepository(dsn string, commandTimeoutMS int, logger *observability.Logger) (*Repository, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Одно соединение без ротации: иначе ":memory:" терял бы данные при переоткрытии
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := db.ExecContext(ctx, `PRAGMA foreign_keys = ON`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
+	}
+
+	return &Repository{
+		db:             db,
+		commandTimeout: time.Duration(commandTimeoutMS) * time.Millisecond,
+		logger:         logger,
+		langUIDs:       make(map[string]int),
+	}, nil
+}
+
+// Migrator возвращает runner встроенных миграций схемы
+func (r *Repository) Migrator() (*migrate.Runner, error) {
+	return migrate.NewRunner(r.db, migrate.SQLite, migrationsFS, "migrations", r.logger)
+}
+
+// RegisterLanguages добавляет языки из конфига в tbl_ref_languages
+func (r *Repository) RegisterLanguages(ctx context.Context, languages []string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	for _, alias := range languages {
+		_, err := r.db.ExecContext(ctx, `INSERT OR IGNORE INTO tbl_ref_languages (alias) VALUES (?)`, alias)
+		if err != nil {
+			return fmt.Errorf("failed to register language %s: %w", alias, err)
+		}
+	}
+
+	return nil
+}
+
+// UpsertCard сохраняет или обновляет карточку
+func (r *Repository) UpsertCard(ctx context.Context, card *storage.ArticleCard) (storage.UpsertResult, error) {
+	results, err := r.UpsertCards(ctx, []*storage.ArticleCard{card})
+	if err != nil {
+		return storage.UpsertUnchanged, err
+	}
+	return results[0], nil
+}
+
+// UpsertCards сохраняет карточки в одной транзакции: либо все, либо ни одной
+func (r *Repository) UpsertCards(ctx context.Context, cards []*storage.ArticleCard) ([]storage.UpsertResult, error) {
+	if len(cards) == 0 {
+		return nil, nil
+	}
+
+	// Таймаут команды — на каждую карточку пакета
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout*time.Duration(len(cards)))
+	defer cancel()
+
+	// Языки получаем до транзакции: соединение одно, запрос вне tx ждал бы её завершения
+	languageUIDs := make([]int, len(cards))
+	for i, card := range cards {
+		languageUID, err := r.getLanguageUID(ctx, card.Language)
+		if err != nil {
+			r.logger.Error("Failed to get language UID",
+				"language", card.Language,
+				"error", err.Error(),
+			)
+			return nil, err
+		}
+		languageUIDs[i] = languageUID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		// После Commit откат вернёт sql.ErrTxDone — это ожидаемо
+		_ = tx.Rollback()
+	}()
+
+	results := make([]storage.UpsertResult, 0, len(cards))
+	for i, card := range cards {
+		result, err := upsert(ctx, tx, languageUIDs[i], card)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upsert %s: %w", card.CanonicalURL, err)
+		}
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return results, nil
+}
+
+// upsert сохраняет одну карточку в транзакции.
+// Строка обновляется только при изменении check_sum; предыдущая версия уходит в tbl_news_revisions,
+// если изменились title, text или thumbnail_url (иначе сменилась только позиция или байты миниатюры).
+// Поля детальной страницы не затираются, если в карточке они пустые
+func upsert(ctx context.Context, tx *sql.Tx, languageUID int, card *storage.ArticleCard) (storage.UpsertResult, error) {
+	var title, text, thumbnailURL, checkSum string
+	err := tx.QueryRowContext(ctx,
+		`SELECT title, text, thumbnail_url, check_sum FROM tbl_news WHERE url = ?`,
+		card.CanonicalURL,
+	).Scan(&title, &text, &thumbnailURL, &checkSum)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO tbl_news (language_uid, sequence_num, dt, title, text, url, thumbnail_url, check_sum, body, og_image_url, detail_dt, thumbnail_data)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?)`,
+			languageUID,
+			card.SequenceNum,
+			formatTime(card.Date),
+			card.Title,
+			card.Text,
+			card.CanonicalURL,
+			card.ImageURL,
+			card.CheckSum,
+			card.Body,
+			card.OgImageURL,
+			nullTime(card.DetailDate),
+			card.ImageData,
+		)
+		if err != nil {
+			return storage.UpsertUnchanged, fmt.Errorf("failed to insert card: %w", err)
+		}
+		return storage.UpsertInserted, nil
+
+	case err != nil:
+		return storage.UpsertUnchanged, fmt.Errorf("failed to query card: %w", err)
+
+	case checkSum == card.CheckSum:
+		return storage.UpsertUnchanged, nil
+	}
+
+	now := formatTime(time.Now())
+
+	result := storage.UpsertUnchanged
+	// Пустой text в обновлении — сохранённое превью остаётся (см. storage.ArticleCard.Text)
+	textChanged := card.Text != "" && text != card.Text
+	if title != card.Title || textChanged || thumbnailURL != card.ImageURL {
+		result = storage.UpsertUpdated
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO tbl_news_revisions (url, title, text, thumbnail_url, check_sum, observed_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			card.CanonicalURL, title, text, thumbnailURL, checkSum, now,
+		)
+		if err != nil {
+			return storage.UpsertUnchanged, fmt.Errorf("failed to insert revision: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE tbl_news SET
+			language_uid = ?,
+			sequence_num = ?,
+			dt = ?,
+			title = ?,
+			text = COALESCE(NULLIF(?, ''), text),
+			thumbnail_url = ?,
+			check_sum = ?,
+			body = COALESCE(NULLIF(?, ''), body),
+			og_image_url = COALESCE(NULLIF(?, ''), og_image_url),
+			detail_dt = COALESCE(?, detail_dt),
+			thumbnail_data = COALESCE(?, thumbnail_data),
+			updated_at = ?
+		WHERE url = ?`,
+		languageUID,
+		card.SequenceNum,
+		formatTime(card.Date),
+		card.Title,
+		card.Text,
+		card.ImageURL,
+		card.CheckSum,
+		card.Body,
+		card.OgImageURL,
+		nullTime(card.DetailDate),
+		card.ImageData,
+		now,
+		card.CanonicalURL,
+	)
+	if err != nil {
+		return storage.UpsertUnchanged, fmt.Errorf("failed to update card: %w", err)
+	}
+
+	return result, nil
+}
+
+// ExistsByURL проверяет наличие карточки по URL
+func (r *Repository) ExistsByURL(ctx context.Context, url string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tbl_news WHERE url = ?)`, url).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetUpdatedAt возвращает время последней записи карточки по URL
+func (r *Repository) GetUpdatedAt(ctx context.Context, url string) (time.Time, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	var updatedAt string
+	err := r.db.QueryRowContext(ctx, `SELECT updated_at FROM tbl_news WHERE url = ?`, url).Scan(&updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	parsed, err := parseTime(updatedAt)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return parsed, true, nil
+}
+
+// GetLatestKnownDate получает последнюю загруженную дату для языка
+func (r *Repository) GetLatestKnownDate(ctx context.Context, lang string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	languageUID, err := r.getLanguageUID(ctx, lang)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latestDate sql.NullString
+	err = r.db.QueryRowContext(ctx, `SELECT MAX(dt) FROM tbl_news WHERE language_uid = ?`, languageUID).Scan(&latestDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	if !latestDate.Valid {
+		// Если нет данных, возвращаем время 1 года назад
+		return time.Now().UTC().AddDate(-1, 0, 0), nil
+	}
+
+	return parseTime(latestDate.String)
+}
+
+// GetCardCount получает количество загруженных карточек для языка
+func (r *Repository) GetCardCount(ctx context.Context, lang string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	languageUID, err := r.getLanguageUID(ctx, lang)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tbl_news WHERE language_uid = ?`, languageUID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	return count, nil
+}
+
+// UpdateNewsCheckSum — аналог USP_UpdateNewsCheckSum: пересчитывает
+// агрегированную контрольную сумму новостей по каждому языку.
+// В SQLite нет sha256, поэтому сумма считается в Go (тот же порядок dt, url, что и в postgres)
+func (r *Repository) UpdateNewsCheckSum(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT language_uid, check_sum FROM tbl_news ORDER BY language_uid, dt, url`)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute UpdateNewsCheckSum: %w", err)
+	}
+
+	type aggregate struct {
+		languageUID int
+		count       int
+		sums        strings.Builder
+	}
+
+	var aggregates []*aggregate
+	for rows.Next() {
+		var languageUID int
+		var checkSum string
+		if err := rows.Scan(&languageUID, &checkSum); err != nil {
+			_ = rows.Close()
+			return "", fmt.Errorf("failed to scan checksum: %w", err)
+		}
+
+		if len(aggregates) == 0 || aggregates[len(aggregates)-1].languageUID != languageUID {
+			aggregates = append(aggregates, &aggregate{languageUID: languageUID})
+		}
+		current := aggregates[len(aggregates)-1]
+		current.count++
+		current.sums.WriteString(checkSum)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return "", fmt.Errorf("failed to read checksums: %w", err)
+	}
+	_ = rows.Close()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := formatTime(time.Now())
+	for _, a := range aggregates {
+		sum := sha256.Sum256([]byte(a.sums.String()))
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO tbl_news_checksums (language_uid, news_count, check_sum, updated_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (language_uid) DO UPDATE SET
+				news_count = excluded.news_count,
+				check_sum = excluded.check_sum,
+				updated_at = excluded.updated_at`,
+			a.languageUID, a.count, hex.EncodeToString(sum[:]), now,
+		)
+		if err != nil {
+			return "", fmt.Errorf("failed to execute UpdateNewsCheckSum: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return fmt.Sprintf("checksums updated for %d languages", len(aggregates)), nil
+}
+
+// getLanguageUID получает UID языка по коду (ru, ky)
+func (r *Repository) getLanguageUID(ctx context.Context, langAlias string) (int, error) {
+	r.langMu.RLock()
+	uid, ok := r.langUIDs[langAlias]
+	r.langMu.RUnlock()
+	if ok {
+		return uid, nil
+	}
+
+	err := r.db.QueryRowContext(ctx, `SELECT uid FROM tbl_ref_languages WHERE alias = ?`, langAlias).Scan(&uid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("language not found: %s", langAlias)
+		}
+		return 0, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	r.langMu.Lock()
+	r.langUIDs[langAlias] = uid
+	r.langMu.Unlock()
+
+	return uid, nil
+}
+
+// ListRevisions возвращает предыдущие версии карточки по URL (новые первыми)
+func (r *Repository) ListRevisions(ctx context.Context, url string) ([]storage.Revision, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	query := `
+		SELECT url, title, text, thumbnail_url, check_sum, observed_at
+		FROM tbl_news_revisions
+		WHERE url = ?
+		ORDER BY observed_at DESC, uid DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var revisions []storage.Revision
+	for rows.Next() {
+		var revision storage.Revision
+		var observedAt string
+		if err := rows.Scan(&revision.URL, &revision.Title, &revision.Text, &revision.ImageURL, &revision.CheckSum, &observedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		if revision.ObservedAt, err = parseTime(observedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// RecordRun сохраняет запись о проходе в историю запусков (tbl_parser_runs)
+func (r *Repository) RecordRun(ctx context.Context, run *storage.RunRecord) error {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	languageUID, err := r.getLanguageUID(ctx, run.Language)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO tbl_parser_runs (language_uid, started_at, finished_at, total_pages, total_cards, old_cards, cached_pages, new_cards, updated_cards, unchanged_cards, stopped_reason, error_text)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		languageUID,
+		formatTime(run.StartedAt),
+		formatTime(run.FinishedAt),
+		run.TotalPages,
+		run.TotalCards,
+		run.OldCards,
+		run.CachedPages,
+		run.NewCards,
+		run.UpdatedCards,
+		run.UnchangedCards,
+		run.StoppedReason,
+		run.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert run record: %w", err)
+	}
+
+	return nil
+}
+
+// Ping проверяет соединение с БД
+func (r *Repository) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с БД
+func (r *Repository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
+
+// formatTime приводит время к текстовому формату колонок (UTC)
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// nullTime — NULL для нулевого времени (неизвестная дата детальной страницы)
+func nullTime(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(t), Valid: true}
+}
+
+// parseTime разбирает дату из текстовой колонки
+func parseTime(value string) (time.Time, error) {
+	// .999999 — дробная часть необязательна (значения по умолчанию CURRENT_TIMESTAMP)
+	t, err := time.ParseInLocation("2006-01-02 15:04:05.999999", value, time.UTC)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse time %q: %w", value, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,193 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"oshcity-news-parser/internal/observability"
+	"oshcity-news-parser/internal/storage"
+	"oshcity-news-parser/internal/storage/storagetest"
+)
+
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	repo, err := NewRepository(":memory:", 5000, observability.NewLogger("", "error", 0, 0, 0))
+	if err != nil {
+		t.Fatalf("NewRepository error: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+
+	ctx := context.Background()
+	runner, err := repo.Migrator()
+	if err != nil {
+		t.Fatalf("Migrator error: %v", err)
+	}
+	if _, err := runner.Up(ctx); err != nil {
+		t.Fatalf("Up error: %v", err)
+	}
+	if err := runner.Check(ctx); err != nil {
+		t.Fatalf("Check error: %v", err)
+	}
+	if err := repo.RegisterLanguages(ctx, []string{"ru", "kg"}); err != nil {
+		t.Fatalf("RegisterLanguages error: %v", err)
+	}
+
+	return repo
+}
+
+func TestContract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Repository {
+		return newTestRepository(t)
+	})
+}
+
+func TestUpsertCard(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	date := time.Date(2025, 10, 18, 9, 30, 0, 0, time.UTC)
+	card := &storage.ArticleCard{
+		CanonicalURL: "https://oshcity.gov.kg/ru/novosti/1/",
+		Title:        "Заголовок",
+		Text:         "Текст",
+		Date:         date,
+		Language:     "ru",
+		CheckSum:     "a",
+		Body:         "Полный текст",
+	}
+
+	tests := []struct {
+		name     string
+		title    string
+		checkSum string
+		body     string
+		want     storage.UpsertResult
+	}{
+		{name: "insert", title: "Заголовок", checkSum: "a", body: "Полный текст", want: storage.UpsertInserted},
+		{name: "same checksum", title: "Заголовок", checkSum: "a", want: storage.UpsertUnchanged},
+		{name: "changed title", title: "Новый заголовок", checkSum: "b", want: storage.UpsertUpdated},
+	}
+
+	for _, tt := range tests {
+		card.Title = tt.title
+		card.CheckSum = tt.checkSum
+		card.Body = tt.body
+		got, err := repo.UpsertCard(ctx, card)
+		if err != nil {
+			t.Fatalf("%s: UpsertCard error: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: UpsertCard = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	revisions, err := repo.ListRevisions(ctx, card.CanonicalURL)
+	if err != nil {
+		t.Fatalf("ListRevisions error: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].CheckSum != "a" {
+		t.Errorf("revisions = %+v, want one revision with checksum a", revisions)
+	}
+
+	// Пустой body в обновлении не затирает сохранённый
+	var body string
+	if err := repo.db.QueryRowContext(ctx, `SELECT body FROM tbl_news WHERE url = ?`, card.CanonicalURL).Scan(&body); err != nil {
+		t.Fatalf("select body error: %v", err)
+	}
+	if body != "Полный текст" {
+		t.Errorf("body = %q, want preserved", body)
+	}
+
+	latest, err := repo.GetLatestKnownDate(ctx, "ru")
+	if err != nil {
+		t.Fatalf("GetLatestKnownDate error: %v", err)
+	}
+	if !latest.Equal(date) {
+		t.Errorf("GetLatestKnownDate = %v, want %v", latest, date)
+	}
+
+	exists, err := repo.ExistsByURL(ctx, card.CanonicalURL)
+	if err != nil || !exists {
+		t.Errorf("ExistsByURL = %v, %v; want true", exists, err)
+	}
+}
+
+func TestUpsertCardsRollback(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	cards := []*storage.ArticleCard{
+		{CanonicalURL: "https://oshcity.gov.kg/ru/novosti/1/", Title: "1", Date: time.Now(), Language: "ru", CheckSum: "a"},
+		{CanonicalURL: "https://oshcity.gov.kg/ru/novosti/2/", Title: "2", Date: time.Now(), Language: "xx", CheckSum: "b"},
+	}
+
+	if _, err := repo.UpsertCards(ctx, cards); err == nil {
+		t.Fatal("expected error for unknown language")
+	}
+
+	count, err := repo.GetCardCount(ctx, "ru")
+	if err != nil {
+		t.Fatalf("GetCardCount error: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("GetCardCount = %d, want 0", count)
+	}
+}
+
+func TestUpdateNewsCheckSum(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	cards := []*storage.ArticleCard{
+		{CanonicalURL: "https://oshcity.gov.kg/ru/novosti/1/", Date: time.Now(), Language: "ru", CheckSum: "a"},
+		{CanonicalURL: "https://oshcity.gov.kg/ky/1/", Date: time.Now(), Language: "kg", CheckSum: "b"},
+	}
+	if _, err := repo.UpsertCards(ctx, cards); err != nil {
+		t.Fatalf("UpsertCards error: %v", err)
+	}
+
+	msg, err := repo.UpdateNewsCheckSum(ctx)
+	if err != nil {
+		t.Fatalf("UpdateNewsCheckSum error: %v", err)
+	}
+	if msg != "checksums updated for 2 languages" {
+		t.Errorf("UpdateNewsCheckSum = %q", msg)
+	}
+
+	var count int
+	if err := repo.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tbl_news_checksums`).Scan(&count); err != nil {
+		t.Fatalf("select checksums error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("tbl_news_checksums rows = %d, want 2", count)
+	}
+}
+
+func TestRecordRun(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	run := &storage.RunRecord{
+		Language:       "ru",
+		StartedAt:      time.Now(),
+		FinishedAt:     time.Now(),
+		TotalCards:     10,
+		NewCards:       1,
+		UpdatedCards:   2,
+		UnchangedCards: 7,
+	}
+	if err := repo.RecordRun(ctx, run); err != nil {
+		t.Fatalf("RecordRun error: %v", err)
+	}
+
+	var newCards, updatedCards, unchangedCards int
+	err := repo.db.QueryRowContext(ctx, `SELECT new_cards, updated_cards, unchanged_cards FROM tbl_parser_runs`).Scan(&newCards, &updatedCards, &unchangedCards)
+	if err != nil {
+		t.Fatalf("failed to read run record: %v", err)
+	}
+	if newCards != 1 || updatedCards != 2 || unchangedCards != 7 {
+		t.Errorf("new, updated, unchanged = %d, %d, %d; want 1, 2, 7", newCards, updatedCards, unchangedCards)
+	}
+}
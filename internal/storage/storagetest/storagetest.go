@@ -0,0 +1,415 @@
+// Package storagetest — общий набор проверок контракта storage.Repository.
+// Любой драйвер подключает его в своём _test.go, чтобы проверяться на тех же ожиданиях,
+// что и эталонная реализация в памяти (storage/memory)
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"oshcity-news-parser/internal/storage"
+)
+
+// Factory возвращает пустое хранилище с зарегистрированными языками "ru" и "kg".
+// Освобождение ресурсов — через t.Cleanup
+type Factory func(t *testing.T) storage.Repository
+
+// baseDate — даты в наборе без долей секунды и в UTC: так их без потерь хранят все драйверы
+var baseDate = time.Date(2025, 10, 18, 9, 30, 0, 0, time.UTC)
+
+// Run выполняет все проверки контракта на хранилищах из newRepo
+func Run(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo storage.Repository)
+	}{
+		{name: "Insert", fn: testInsert},
+		{name: "UpdateChangedChecksum", fn: testUpdateChangedChecksum},
+		{name: "IdempotentUpsert", fn: testIdempotentUpsert},
+		{name: "SequenceNumOnlyChange", fn: testSequenceNumOnlyChange},
+		{name: "ThumbnailBytesOnlyChange", fn: testThumbnailBytesOnlyChange},
+		{name: "EmptyTextKeepsStored", fn: testEmptyTextKeepsStored},
+		{name: "UpsertCardsBatch", fn: testUpsertCardsBatch},
+		{name: "UpsertCardsRepeatedURL", fn: testUpsertCardsRepeatedURL},
+		{name: "ExistsByURL", fn: testExistsByURL},
+		{name: "UpdatedAt", fn: testUpdatedAt},
+		{name: "LatestKnownDateEmpty", fn: testLatestKnownDateEmpty},
+		{name: "LatestKnownDatePopulated", fn: testLatestKnownDatePopulated},
+		{name: "CardCountPerLanguage", fn: testCardCountPerLanguage},
+		{name: "UpdateNewsCheckSum", fn: testUpdateNewsCheckSum},
+		{name: "RecordRun", fn: testRecordRun},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// newCard возвращает карточку с уникальным URL для языка
+func newCard(lang string, n int, checkSum string) *storage.ArticleCard {
+	return &storage.ArticleCard{
+		CanonicalURL: fmt.Sprintf("https://oshcity.gov.kg/%s/novosti/%d/", lang, n),
+		Title:        fmt.Sprintf("Новость %d", n),
+		Text:         "Текст новости",
+		ImageURL:     fmt.Sprintf("https://oshcity.gov.kg/wp-content/uploads/%d.jpg", n),
+		Date:         baseDate.AddDate(0, 0, n),
+		Language:     lang,
+		SequenceNum:  n,
+		CheckSum:     checkSum,
+	}
+}
+
+func upsert(t *testing.T, repo storage.Repository, card *storage.ArticleCard, want storage.UpsertResult) {
+	t.Helper()
+
+	got, err := repo.UpsertCard(context.Background(), card)
+	if err != nil {
+		t.Fatalf("UpsertCard(%s) error: %v", card.CanonicalURL, err)
+	}
+	if got != want {
+		t.Errorf("UpsertCard(%s) = %s, want %s", card.CanonicalURL, got, want)
+	}
+}
+
+func testInsert(t *testing.T, repo storage.Repository) {
+	upsert(t, repo, newCard("ru", 1, "a"), storage.UpsertInserted)
+	upsert(t, repo, newCard("ru", 2, "b"), storage.UpsertInserted)
+
+	count, err := repo.GetCardCount(context.Background(), "ru")
+	if err != nil {
+		t.Fatalf("GetCardCount error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("GetCardCount = %d, want 2", count)
+	}
+}
+
+func testUpdateChangedChecksum(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	card := newCard("ru", 1, "a")
+	upsert(t, repo, card, storage.UpsertInserted)
+
+	changed := newCard("ru", 1, "b")
+	changed.Title = "Исправленный заголовок"
+	upsert(t, repo, changed, storage.UpsertUpdated)
+
+	count, err := repo.GetCardCount(ctx, "ru")
+	if err != nil {
+		t.Fatalf("GetCardCount error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("GetCardCount = %d, want 1 (update must not duplicate)", count)
+	}
+
+	revisions, err := repo.ListRevisions(ctx, card.CanonicalURL)
+	if err != nil {
+		t.Fatalf("ListRevisions error: %v", err)
+	}
+	if len(revisions) != 1 {
+		t.Fatalf("len(revisions) = %d, want 1", len(revisions))
+	}
+	if revisions[0].CheckSum != "a" || revisions[0].Title != card.Title {
+		t.Errorf("revision = %s/%q, want previous version a/%q", revisions[0].CheckSum, revisions[0].Title, card.Title)
+	}
+}
+
+func testIdempotentUpsert(t *testing.T, repo storage.Repository) {
+	card := newCard("ru", 1, "a")
+	upsert(t, repo, card, storage.UpsertInserted)
+	upsert(t, repo, card, storage.UpsertUnchanged)
+	upsert(t, repo, card, storage.UpsertUnchanged)
+
+	revisions, err := repo.ListRevisions(context.Background(), card.CanonicalURL)
+	if err != nil {
+		t.Fatalf("ListRevisions error: %v", err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("len(revisions) = %d, want 0 for unchanged card", len(revisions))
+	}
+}
+
+// testSequenceNumOnlyChange — новая статья сдвигает карточку в листинге: меняются SequenceNum и CheckSum,
+// но не содержимое, поэтому ревизия не создаётся
+func testSequenceNumOnlyChange(t *testing.T, repo storage.Repository) {
+	card := newCard("ru", 1, "a")
+	upsert(t, repo, card, storage.UpsertInserted)
+
+	shifted := newCard("ru", 1, "b")
+	shifted.SequenceNum = 2
+	upsert(t, repo, shifted, storage.UpsertUnchanged)
+	upsert(t, repo, shifted, storage.UpsertUnchanged)
+
+	revisions, err := repo.ListRevisions(context.Background(), card.CanonicalURL)
+	if err != nil {
+		t.Fatalf("ListRevisions error: %v", err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("len(revisions) = %d, want 0 when only SequenceNum changed", len(revisions))
+	}
+}
+
+// testEmptyTextKeepsStored — превью с детальной страницы сохраняется, когда карточка
+// приходит из листинга повторно без текста
+func testThumbnailBytesOnlyChange(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	card := newCard("ru", 1, "a")
+	card.ImageData = []byte{0xFF, 0xD8, 0x01}
+	upsert(t, repo, card, storage.UpsertInserted)
+
+	// Новые байты миниатюры по тому же ImageURL: CheckSum меняется, ревизия не пишется
+	replaced := newCard("ru", 1, "b")
+	replaced.ImageData = []byte{0xFF, 0xD8, 0x02}
+	upsert(t, repo, replaced, storage.UpsertUnchanged)
+
+	// Новый CheckSum сохранён: повтор той же карточки — без изменений
+	upsert(t, repo, replaced, storage.UpsertUnchanged)
+
+	revisions, err := repo.ListRevisions(ctx, card.CanonicalURL)
+	if err != nil {
+		t.Fatalf("ListRevisions error: %v", err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("len(revisions) = %d, want 0 when only thumbnail bytes changed", len(revisions))
+	}
+}
+
+func testEmptyTextKeepsStored(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	card := newCard("ru", 1, "a")
+	card.Text = "Превью с детальной страницы"
+	upsert(t, repo, card, storage.UpsertInserted)
+
+	again := newCard("ru", 1, "b")
+	again.Text = ""
+	again.SequenceNum = 3
+	upsert(t, repo, again, storage.UpsertUnchanged)
+
+	// Следующее изменение текста сохраняет в ревизии именно превью
+	changed := newCard("ru", 1, "c")
+	changed.Text = "Новое превью"
+	upsert(t, repo, changed, storage.UpsertUpdated)
+
+	revisions, err := repo.ListRevisions(ctx, card.CanonicalURL)
+	if err != nil {
+		t.Fatalf("ListRevisions error: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Text != card.Text {
+		t.Errorf("revisions = %+v, want one revision with text %q", revisions, card.Text)
+	}
+}
+
+func testUpsertCardsBatch(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	upsert(t, repo, newCard("ru", 1, "a"), storage.UpsertInserted)
+
+	changed := newCard("ru", 1, "c")
+	changed.Text = "Исправленный текст"
+	cards := []*storage.ArticleCard{
+		newCard("ru", 1, "a"),
+		newCard("ru", 2, "b"),
+		changed,
+	}
+	results, err := repo.UpsertCards(ctx, cards)
+	if err != nil {
+		t.Fatalf("UpsertCards error: %v", err)
+	}
+
+	want := []storage.UpsertResult{storage.UpsertUnchanged, storage.UpsertInserted, storage.UpsertUpdated}
+	if len(results) != len(want) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(want))
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("results[%d] = %s, want %s", i, results[i], want[i])
+		}
+	}
+
+	// Пакет с неизвестным языком не сохраняется целиком
+	failing := []*storage.ArticleCard{newCard("ru", 3, "d"), newCard("xx", 4, "e")}
+	if _, err := repo.UpsertCards(ctx, failing); err == nil {
+		t.Error("UpsertCards with unknown language: expected error")
+	}
+	exists, err := repo.ExistsByURL(ctx, failing[0].CanonicalURL)
+	if err != nil {
+		t.Fatalf("ExistsByURL error: %v", err)
+	}
+	if exists {
+		t.Error("card from failed batch must not be stored")
+	}
+}
+
+func testUpsertCardsRepeatedURL(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+
+	// Повтор URL в пакете применяется поверх предыдущей карточки, как при поочерёдном сохранении
+	changed := newCard("ru", 1, "b")
+	changed.Text = "Исправленный текст"
+	cards := []*storage.ArticleCard{newCard("ru", 1, "a"), newCard("ru", 2, "a"), changed}
+	results, err := repo.UpsertCards(ctx, cards)
+	if err != nil {
+		t.Fatalf("UpsertCards error: %v", err)
+	}
+
+	want := []storage.UpsertResult{storage.UpsertInserted, storage.UpsertInserted, storage.UpsertUpdated}
+	if len(results) != len(want) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(want))
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("results[%d] = %s, want %s", i, results[i], want[i])
+		}
+	}
+
+	count, err := repo.GetCardCount(ctx, "ru")
+	if err != nil {
+		t.Fatalf("GetCardCount error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("GetCardCount = %d, want 2", count)
+	}
+}
+
+func testExistsByURL(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	card := newCard("ru", 1, "a")
+
+	tests := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{name: "before insert", url: card.CanonicalURL, want: false},
+		{name: "after insert", url: card.CanonicalURL, want: true},
+		{name: "other url", url: newCard("ru", 2, "b").CanonicalURL, want: false},
+	}
+
+	for i, tt := range tests {
+		if i == 1 {
+			upsert(t, repo, card, storage.UpsertInserted)
+		}
+		got, err := repo.ExistsByURL(ctx, tt.url)
+		if err != nil {
+			t.Fatalf("%s: ExistsByURL error: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: ExistsByURL = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testUpdatedAt(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	card := newCard("ru", 1, "a")
+
+	if _, found, err := repo.GetUpdatedAt(ctx, card.CanonicalURL); err != nil || found {
+		t.Fatalf("GetUpdatedAt before insert = found %v, error %v; want not found", found, err)
+	}
+
+	upsert(t, repo, card, storage.UpsertInserted)
+	inserted, found, err := repo.GetUpdatedAt(ctx, card.CanonicalURL)
+	if err != nil || !found {
+		t.Fatalf("GetUpdatedAt after insert = found %v, error %v", found, err)
+	}
+	// Время записи ставит БД: допускаем расхождение часов сервера
+	if d := time.Since(inserted); d < -time.Hour || d > time.Hour {
+		t.Errorf("GetUpdatedAt after insert = %v, want about now", inserted)
+	}
+
+	changed := newCard("ru", 1, "b")
+	changed.Title = "Новость 1 (исправлено)"
+	upsert(t, repo, changed, storage.UpsertUpdated)
+	updated, _, err := repo.GetUpdatedAt(ctx, card.CanonicalURL)
+	if err != nil {
+		t.Fatalf("GetUpdatedAt after update error: %v", err)
+	}
+	if updated.Before(inserted) {
+		t.Errorf("GetUpdatedAt after update = %v, before insert time %v", updated, inserted)
+	}
+}
+
+func testLatestKnownDateEmpty(t *testing.T, repo storage.Repository) {
+	got, err := repo.GetLatestKnownDate(context.Background(), "ru")
+	if err != nil {
+		t.Fatalf("GetLatestKnownDate error: %v", err)
+	}
+
+	// Без данных — год назад от текущего момента
+	want := time.Now().UTC().AddDate(-1, 0, 0)
+	if diff := got.Sub(want); diff < -time.Minute || diff > time.Minute {
+		t.Errorf("GetLatestKnownDate = %v, want about %v", got, want)
+	}
+}
+
+func testLatestKnownDatePopulated(t *testing.T, repo storage.Repository) {
+	upsert(t, repo, newCard("ru", 3, "a"), storage.UpsertInserted)
+	upsert(t, repo, newCard("ru", 5, "b"), storage.UpsertInserted)
+	upsert(t, repo, newCard("ru", 4, "c"), storage.UpsertInserted)
+	// Более свежая карточка другого языка не влияет на "ru"
+	upsert(t, repo, newCard("kg", 9, "d"), storage.UpsertInserted)
+
+	got, err := repo.GetLatestKnownDate(context.Background(), "ru")
+	if err != nil {
+		t.Fatalf("GetLatestKnownDate error: %v", err)
+	}
+	if want := baseDate.AddDate(0, 0, 5); !got.Equal(want) {
+		t.Errorf("GetLatestKnownDate = %v, want %v", got, want)
+	}
+}
+
+func testCardCountPerLanguage(t *testing.T, repo storage.Repository) {
+	upsert(t, repo, newCard("ru", 1, "a"), storage.UpsertInserted)
+	upsert(t, repo, newCard("ru", 2, "b"), storage.UpsertInserted)
+	upsert(t, repo, newCard("kg", 1, "c"), storage.UpsertInserted)
+
+	tests := []struct {
+		lang string
+		want int
+	}{
+		{lang: "ru", want: 2},
+		{lang: "kg", want: 1},
+	}
+
+	for _, tt := range tests {
+		got, err := repo.GetCardCount(context.Background(), tt.lang)
+		if err != nil {
+			t.Fatalf("GetCardCount(%s) error: %v", tt.lang, err)
+		}
+		if got != tt.want {
+			t.Errorf("GetCardCount(%s) = %d, want %d", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func testUpdateNewsCheckSum(t *testing.T, repo storage.Repository) {
+	upsert(t, repo, newCard("ru", 1, "a"), storage.UpsertInserted)
+	upsert(t, repo, newCard("kg", 1, "b"), storage.UpsertInserted)
+
+	if _, err := repo.UpdateNewsCheckSum(context.Background()); err != nil {
+		t.Errorf("UpdateNewsCheckSum error: %v", err)
+	}
+}
+
+func testRecordRun(t *testing.T, repo storage.Repository) {
+	run := &storage.RunRecord{
+		Language:       "ru",
+		StartedAt:      baseDate,
+		FinishedAt:     baseDate.Add(time.Minute),
+		TotalPages:     3,
+		TotalCards:     30,
+		NewCards:       2,
+		UnchangedCards: 27,
+		StoppedReason:  "no next link at page 3",
+	}
+	if err := repo.RecordRun(context.Background(), run); err != nil {
+		t.Errorf("RecordRun error: %v", err)
+	}
+
+	failed := &storage.RunRecord{Language: "ru", StartedAt: baseDate, FinishedAt: baseDate, Error: "context canceled"}
+	if err := repo.RecordRun(context.Background(), failed); err != nil {
+		t.Errorf("RecordRun (failed run) error: %v", err)
+	}
+}
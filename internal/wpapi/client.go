@@ -0,0 +1,151 @@
+package wpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"oshcity-news-parser/internal/config"
+	"oshcity-news-parser/internal/fetcher"
+	"oshcity-news-parser/internal/normalize"
+	"oshcity-news-parser/internal/observability"
+	"oshcity-news-parser/internal/scraper"
+)
+
+// Client получает посты из WordPress REST API (/wp-json/wp/v2/posts)
+type Client struct {
+	fetcher *fetcher.Fetcher
+	logger  *observability.Logger
+}
+
+// Post — поля поста WP REST API, нужные для scraper.Card
+type Post struct {
+	ID       int      `json:"id"`
+	Date     string   `json:"date"`
+	Link     string   `json:"link"`
+	Title    rendered `json:"title"`
+	Excerpt  rendered `json:"excerpt"`
+	Embedded struct {
+		FeaturedMedia []struct {
+			SourceURL string `json:"source_url"`
+		} `json:"wp:featuredmedia"`
+	} `json:"_embedded"`
+}
+
+type rendered struct {
+	Rendered string `json:"rendered"`
+}
+
+// Page — одна страница выдачи API
+type Page struct {
+	Cards      []*scraper.Card
+	TotalPages int // из заголовка X-WP-TotalPages (0 — неизвестно)
+}
+
+func NewClient(f *fetcher.Fetcher, logger *observability.Logger) *Client {
+	return &Client{
+		fetcher: f,
+		logger:  logger,
+	}
+}
+
+// FetchPage загружает страницу постов, опубликованных после after
+func (c *Client) FetchPage(ctx context.Context, langCfg *config.LanguageConfig, page int, after time.Time) (*Page, error) {
+	pageURL, err := BuildPageURL(&langCfg.WPAPI, page, after)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.fetcher.FetchJSON(ctx, pageURL, langCfg.AcceptLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	// WP отвечает 400 rest_post_invalid_page_number, если страница за пределами выдачи
+	if resp.StatusCode == http.StatusBadRequest && page > 1 {
+		return &Page{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected WP API status: %d", resp.StatusCode)
+	}
+
+	var posts []Post
+	if err := json.Unmarshal(resp.Body, &posts); err != nil {
+		return nil, fmt.Errorf("failed to decode WP API response: %w", err)
+	}
+
+	totalPages, _ := strconv.Atoi(resp.Headers.Get("X-WP-TotalPages"))
+
+	c.logger.Debug("WP API page fetched",
+		"language", langCfg.Name,
+		"page", page,
+		"posts", len(posts),
+		"total_pages", totalPages,
+	)
+
+	return &Page{
+		Cards:      PostsToCards(posts),
+		TotalPages: totalPages,
+	}, nil
+}
+
+// BuildPageURL строит URL запроса с параметрами page / per_page / after
+func BuildPageURL(apiCfg *config.WPAPIConfig, page int, after time.Time) (string, error) {
+	u, err := url.Parse(apiCfg.Endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid WP API endpoint: %w", err)
+	}
+
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(apiCfg.PerPage))
+	query.Set("orderby", "date")
+	query.Set("order", "desc")
+	query.Set("_embed", "wp:featuredmedia")
+	if !after.IsZero() {
+		query.Set("after", after.UTC().Format("2006-01-02T15:04:05"))
+	}
+	if apiCfg.Lang != "" {
+		query.Set("lang", apiCfg.Lang)
+	}
+	if apiCfg.Categories != "" {
+		query.Set("categories", apiCfg.Categories)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// PostsToCards преобразует посты API в карточки листинга
+func PostsToCards(posts []Post) []*scraper.Card {
+	cards := make([]*scraper.Card, 0, len(posts))
+	for i, post := range posts {
+		card := &scraper.Card{
+			Title:       normalize.HTMLToText(post.Title.Rendered),
+			URL:         normalize.NormalizeURL(post.Link),
+			Text:        normalize.HTMLToText(post.Excerpt.Rendered),
+			DateRaw:     post.Date,
+			SequenceNum: i + 1,
+		}
+
+		if len(post.Embedded.FeaturedMedia) > 0 {
+			card.ThumbnailURL = normalize.NormalizeURL(post.Embedded.FeaturedMedia[0].SourceURL)
+		}
+
+		// date — локальное время сайта без зоны; как и в листинге, храним только день
+		if t, err := time.Parse("2006-01-02T15:04:05", post.Date); err == nil {
+			card.PublishedAt = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		}
+
+		if card.Text == "" {
+			card.Text = card.Title
+		}
+
+		cards = append(cards, card)
+	}
+	return cards
+}
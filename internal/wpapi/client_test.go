@@ -0,0 +1,99 @@
+package wpapi
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"oshcity-news-parser/internal/config"
+)
+
+const testPosts = `[
+	{
+		"id": 101,
+		"date": "2025-10-18T14:30:00",
+		"link": "https://oshcity.gov.kg/ru/novosti/test-news/#comments",
+		"title": {"rendered": "Мэрия &#8211; новости"},
+		"excerpt": {"rendered": "<p>Краткое  описание&hellip;</p>\n"},
+		"_embedded": {"wp:featuredmedia": [{"source_url": "https://oshcity.gov.kg/wp-content/uploads/photo.jpg"}]}
+	},
+	{
+		"id": 102,
+		"date": "2025-10-17T09:00:00",
+		"link": "https://oshcity.gov.kg/ru/novosti/other/",
+		"title": {"rendered": "Без описания"},
+		"excerpt": {"rendered": ""}
+	}
+]`
+
+func TestPostsToCards(t *testing.T) {
+	var posts []Post
+	if err := json.Unmarshal([]byte(testPosts), &posts); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+
+	cards := PostsToCards(posts)
+	if len(cards) != 2 {
+		t.Fatalf("len(cards) = %d, want 2", len(cards))
+	}
+
+	first := cards[0]
+	if first.Title != "Мэрия – новости" {
+		t.Errorf("Title = %q", first.Title)
+	}
+	if first.URL != "https://oshcity.gov.kg/ru/novosti/test-news/" {
+		t.Errorf("URL = %q", first.URL)
+	}
+	if first.Text != "Краткое описание…" {
+		t.Errorf("Text = %q", first.Text)
+	}
+	if first.ThumbnailURL != "https://oshcity.gov.kg/wp-content/uploads/photo.jpg" {
+		t.Errorf("ThumbnailURL = %q", first.ThumbnailURL)
+	}
+	if !first.PublishedAt.Equal(time.Date(2025, 10, 18, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("PublishedAt = %v", first.PublishedAt)
+	}
+	if first.SequenceNum != 1 {
+		t.Errorf("SequenceNum = %d, want 1", first.SequenceNum)
+	}
+
+	// Без excerpt текст берётся из заголовка, как в HTML-листинге
+	if cards[1].Text != "Без описания" {
+		t.Errorf("Text fallback = %q", cards[1].Text)
+	}
+}
+
+func TestBuildPageURL(t *testing.T) {
+	apiCfg := &config.WPAPIConfig{
+		Endpoint: "https://oshcity.gov.kg/wp-json/wp/v2/posts",
+		PerPage:  20,
+		Lang:     "ru",
+	}
+
+	pageURL, err := BuildPageURL(apiCfg, 3, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("BuildPageURL error: %v", err)
+	}
+
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		t.Fatalf("invalid URL: %v", err)
+	}
+
+	query := u.Query()
+	expected := map[string]string{
+		"page":     "3",
+		"per_page": "20",
+		"after":    "2025-10-01T00:00:00",
+		"lang":     "ru",
+	}
+	for key, value := range expected {
+		if query.Get(key) != value {
+			t.Errorf("query %s = %q, want %q", key, query.Get(key), value)
+		}
+	}
+	if query.Has("categories") {
+		t.Errorf("categories should not be set")
+	}
+}